@@ -45,43 +45,96 @@ func main() {
 	// Инициализируем репозитории и usecases
 	chatRepo := repository.NewPostgresChatRepository(db.DB)
 	userRepo := repository.NewPostgresUserRepository(db.DB)
+	nightModeRepo := repository.NewPostgresNightModeRepository(db.DB)
+	muteRepo := repository.NewPostgresMuteRepository(db.DB)
+	chatRoleRepo := repository.NewPostgresChatRoleRepository(db.DB)
+	chatPermissionRepo := repository.NewPostgresChatPermissionRepository(db.DB)
+	roleSyncOptOutRepo := repository.NewPostgresRoleSyncOptOutRepository(db.DB)
+	chatMemberRepo := repository.NewPostgresChatMemberRepository(db.DB)
+	nickHistoryRepo := repository.NewPostgresNickHistoryRepository(db.DB)
+	chatTextRepo := repository.NewPostgresChatTextRepository(db.DB)
+	faqEntryRepo := repository.NewPostgresFaqEntryRepository(db.DB)
+	faqRejectionRepo := repository.NewPostgresFaqRejectionRepository(db.DB)
+	messageTemplateRepo := repository.NewPostgresMessageTemplateRepository(db.DB)
 	chatUseCase := usecase.NewChatUseCase(chatRepo)
-	userUseCase := usecase.NewUserUseCase(userRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, chatRoleRepo, nickHistoryRepo)
+	permissionUseCase := usecase.NewPermissionUseCase(userUseCase, chatPermissionRepo)
+	nightModeUseCase := usecase.NewNightModeUseCase(nightModeRepo)
+	muteUseCase := usecase.NewMuteUseCase(muteRepo)
+	roleSyncUseCase := usecase.NewRoleSyncUseCase(userUseCase, chatRoleRepo, roleSyncOptOutRepo)
+	chatMemberUseCase := usecase.NewChatMemberUseCase(chatMemberRepo)
+	chatTextUseCase := usecase.NewChatTextUseCase(chatTextRepo)
+	faqUseCase := usecase.NewFaqUseCase(faqEntryRepo, faqRejectionRepo, userUseCase)
+	templateUseCase := usecase.NewTemplateUseCase(messageTemplateRepo)
 
 	// Очередь запросов к апи
-	apiQueue := school.NewAPIQueue(3, time.Second, nil, log)
+	apiQueue := school.NewAPIQueue(3, time.Second, school.RetryPolicy{
+		MaxAttempts: cfg.SchoolRetryAttempts,
+		BaseDelay:   cfg.SchoolRetryBaseDelay,
+		MaxDelay:    cfg.SchoolRetryMaxDelay,
+	}, nil, log)
 	apiQueue.Start(ctx) // Теперь запросики крутятся
 
-	// Инициализируем JWTService
-	jwtService := school.NewJWTService(
+	// Токены Keycloak для School API
+	schoolTokens := school.NewTokenSource(
 		cfg.SchoolUsername,
 		cfg.SchoolPassword,
-		cfg.SchoolTokenURL,   // URL для аутентификации
-		cfg.SchoolBaseApiURL, // URL для API запросов
-		log,
+		cfg.SchoolTokenURL, // URL для аутентификации
 		apiQueue,
+		log,
 	)
 
 	// Выполняем первичную аутентификацию
-	if err := jwtService.Authenticate(ctx); err != nil {
+	if err := schoolTokens.Authenticate(ctx); err != nil {
 		log.Error(ctx, "Failed to authenticate with School API: %v", err)
 		os.Exit(1)
 	}
 
+	// Предохранитель: пока School API лежит, запросы не ждут очереди и повторов
+	schoolBreaker := school.NewBreaker(cfg.SchoolBreakerThreshold, cfg.SchoolBreakerCooldown, log)
+
+	// Клиент School API, токены подставляются в каждый запрос
+	schoolClient := school.NewClient(cfg.SchoolBaseApiURL, schoolTokens, apiQueue, schoolBreaker, log)
+
 	// Создаем кеш для идов ThreadID
 	chatCache := cache.NewChatCache()
 
 	// Загружаем данные из базы в кеш
-	if err := chatCache.LoadFromDatabase(ctx, chatUseCase); err != nil {
+	if err := chatCache.LoadFromDatabase(ctx, chatUseCase, chatTextUseCase); err != nil {
 		log.Error(ctx, "Failed to load chats from database: %v", err)
 		os.Exit(1)
 	}
 
+	// Кеш username -> ID для команд модерации
+	userCache := cache.NewUserCache()
+
+	// Профили из School API для /whois и /me
+	profileCache := cache.NewProfileCache(10 * time.Minute)
+
+	// Тексты Морти на всех языках, язык чата берётся из кеша
+	localizer := telegram.NewLocalizer(chatCache)
+
 	// Создаём обработчики
-	userHandler := telegram.NewUserHandler(log, chatUseCase, userUseCase, jwtService)
-	commandHandler := commands.NewCommandHandler(log, chatUseCase, userUseCase, chatCache, userHandler)
+	templates := telegram.NewTemplateRenderer(log, templateUseCase)
+	userHandler := telegram.NewUserHandler(log, chatUseCase, userUseCase, chatMemberUseCase, schoolClient, templates, localizer)
+	memberHandler := telegram.NewMemberHandler(log, chatMemberUseCase, userUseCase, chatCache, userHandler)
+	slowModeHandler := telegram.NewSlowModeHandler(log, permissionUseCase, chatCache)
+	faqResponder := telegram.NewFaqResponder(log, faqUseCase, permissionUseCase, chatCache, localizer)
+	adminSyncer := telegram.NewAdminSyncer(log, chatUseCase, roleSyncUseCase, cfg.RoleSyncInterval)
+	commandHandler := commands.NewCommandHandler(log, chatUseCase, userUseCase, permissionUseCase, nightModeUseCase, muteUseCase, roleSyncUseCase, chatMemberUseCase, chatTextUseCase, faqUseCase, chatCache, userCache, userHandler, adminSyncer, schoolClient, profileCache, templates, localizer)
+	nightModeScheduler := telegram.NewNightModeScheduler(log, nightModeUseCase)
+	schoolWatchdog := telegram.NewSchoolWatchdog(log, schoolBreaker, schoolClient, userHandler, localizer, cfg.AlertsChatID)
 
 	botOptions := []bot.Option{
+		bot.WithMiddlewares(telegram.RememberUsersMiddleware(log, userCache, userUseCase)),
+		// chat_member по умолчанию не приходит, а без него не узнать о киках и банах
+		bot.WithAllowedUpdates(bot.AllowedUpdates{
+			"message",
+			"edited_message",
+			"callback_query",
+			"chat_member",
+			"my_chat_member",
+		}),
 		bot.WithDebugHandler(func(format string, args ...interface{}) {
 			log.Debug(ctx, format, args...)
 		}),
@@ -89,6 +142,10 @@ func main() {
 			log.Error(ctx, err.Error())
 		}),
 		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if update != nil && update.ChatMember != nil {
+				memberHandler.HandleChatMember(ctx, b, update.ChatMember)
+				return
+			}
 			if update == nil || update.Message == nil {
 				return
 			}
@@ -97,6 +154,7 @@ func main() {
 				return
 			}
 			if update.Message != nil && update.Message.LeftChatMember != nil {
+				memberHandler.HandleLeftMessage(ctx, b, update.Message)
 				b.DeleteMessage(ctx, &bot.DeleteMessageParams{
 					ChatID:    update.Message.Chat.ID,
 					MessageID: update.Message.ID,
@@ -111,6 +169,10 @@ func main() {
 				userHandler.HandleNickname(ctx, b, update.Message)
 				return
 			}
+			if slowModeHandler.HandleMessage(ctx, b, update.Message) {
+				return
+			}
+			faqResponder.HandleMessage(ctx, b, update.Message)
 		}),
 	}
 
@@ -120,52 +182,60 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Регистрируем команды
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/morty_come_here", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.HandleCommand(ctx, b, update.Message)
-	})
+	me, err := tgBot.GetMe(ctx)
+	if err != nil {
+		log.Error(ctx, "Failed to get bot info: %v", err)
+		os.Exit(1)
+	}
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/morty_id_topic_here", bot.MatchTypeExact, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.HandleCommand(ctx, b, update.Message)
-	})
+	// Регистрируем команды
+	router := telegram.NewRouter(log, me.Username, localizer)
+	router.Use(
+		telegram.RecoverMiddleware(log),
+		telegram.LoggingMiddleware(log),
+		telegram.RateLimitMiddleware(log, 5, 10*time.Second),
+		telegram.AuthMiddleware(log, permissionUseCase),
+	)
+	router.Register(commandHandler.Commands()...)
+	router.Register(commandHandler.HelpCommand(router))
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/morty_rules", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.HandleCommand(ctx, b, update.Message)
-	})
+	if cfg.Debug {
+		router.Register(telegram.Command{
+			Name: "test",
+			Handler: func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+				log.Debug(ctx, "Test cmd")
+			},
+		})
+	}
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/morty_faq", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.HandleCommand(ctx, b, update.Message)
-	})
+	tgBot.RegisterHandlerMatchFunc(router.Match, router.Handle)
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/mute", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.HandleCommand(ctx, b, update.Message)
-	})
+	// Кнопки под превью правил и FAQ
+	tgBot.RegisterHandler(bot.HandlerTypeCallbackQueryData, commands.TextCallbackPrefix, bot.MatchTypePrefix, commandHandler.HandleTextCallback)
+	// Навигация по темам FAQ
+	tgBot.RegisterHandler(bot.HandlerTypeCallbackQueryData, commands.FaqCallbackPrefix, bot.MatchTypePrefix, commandHandler.HandleFaqCallback)
+	// "Не то" под автоответами
+	tgBot.RegisterHandler(bot.HandlerTypeCallbackQueryData, telegram.AutoFaqCallbackPrefix, bot.MatchTypePrefix, faqResponder.HandleCallback)
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/unmute", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.HandleCommand(ctx, b, update.Message)
-	})
+	// Меню команд в Telegram собирается из тех же описаний, что и /help
+	if err := router.SyncCommandMenus(ctx, tgBot); err != nil {
+		log.Error(ctx, "Failed to set bot commands", "err", err)
+	}
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/faq", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.FaqHandle(ctx, b, update.Message)
-	})
+	// Ночной режим живёт по своему расписанию
+	nightModeScheduler.Start(ctx, tgBot)
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/rules", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.RulesHandle(ctx, b, update.Message)
-	})
+	// Роли сверяются с админами Telegram, если это включено в конфиге
+	adminSyncer.Start(ctx, tgBot)
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/save", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.SaveHandle(ctx, b, update.Message)
-	})
+	// Устаревшие профили School API не копятся в памяти
+	profileCache.Start(ctx)
 
-	tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/role", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		commandHandler.RoleHandle(ctx, b, update.Message)
-	})
+	// Пока School API лежит, новичков не баним, а их ники проверим, когда API оживёт
+	schoolWatchdog.Start(ctx, tgBot)
 
-	if cfg.Debug {
-		tgBot.RegisterHandler(bot.HandlerTypeMessageText, "/test", bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
-			log.Debug(ctx, "Test cmd")
-		})
-	}
+	// Истёкшие муты Telegram снимает сам, нам остаётся почистить реестр
+	muteUseCase.StartCleanup(ctx, 10*time.Minute, log)
 
 	// Запускаем бота
 	defer func() {
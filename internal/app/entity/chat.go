@@ -2,12 +2,16 @@ package entity
 
 import "time"
 
+// ChatLanguageAuto - отвечать каждому на языке его клиента Telegram
+const ChatLanguageAuto = "auto"
+
 type Chat struct {
-	ID         int64     `gorm:"primaryKey"`
-	ChatID     int64     `gorm:"uniqueIndex;not null"`
-	CampusName string    `gorm:"not null"`
-	RulesLink  *string   `gorm:"default:null"`
-	FaqLink    *string   `gorm:"default:null"`
-	ThreadID   int       `gorm:"default:-1"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	ID               int64     `gorm:"primaryKey"`
+	ChatID           int64     `gorm:"uniqueIndex;not null"`
+	CampusName       string    `gorm:"not null"`
+	ThreadID         int       `gorm:"default:-1"`
+	SlowModeDelay    int       `gorm:"not null;default:0"`  // секунды между сообщениями, 0 - выключен
+	AutoFaqThreshold float64   `gorm:"not null;default:0"`  // уверенность для автоответов из FAQ, 0 - выключены
+	Language         string    `gorm:"not null;default:ru"` // язык Морти в чате, auto - язык клиента участника
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
 }
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	MemberStatusPending = "pending" // зашёл, но ещё не прислал ник
+	MemberStatusMember  = "member"  // проверен и сидит в чате
+	MemberStatusLeft    = "left"    // вышел сам
+	MemberStatusKicked  = "kicked"  // удалён без бана
+	MemberStatusBanned  = "banned"  // забанен
+)
+
+// ChatMember - участие пользователя в конкретном чате
+type ChatMember struct {
+	ID         int64      `gorm:"primaryKey"`
+	ChatID     int64      `gorm:"not null"`
+	TelegramID int64      `gorm:"not null"`
+	Status     string     `gorm:"not null"` // pending, member, left, kicked, banned
+	JoinedAt   time.Time  `gorm:"not null"`
+	VerifiedAt *time.Time `gorm:"default:null"`
+	LeftAt     *time.Time `gorm:"default:null"`
+}
+
+// IsActive - пользователь сейчас в чате
+func (m *ChatMember) IsActive() bool {
+	return m.Status == MemberStatusPending || m.Status == MemberStatusMember
+}
+
+// ChatStats - статистика участников чата
+type ChatStats struct {
+	ByStatus    map[string]int64 // сколько участников в каждом статусе
+	JoinedSince int64            // зашли за период
+	LeftSince   int64            // вышли, удалены или забанены за период
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	RoleSourceManual   = "manual"   // выдана командой /role
+	RoleSourceTelegram = "telegram" // синхронизирована с админами Telegram
+)
+
+// ChatRole - роль пользователя в конкретном чате. Глобальная роль в users
+// остаётся только у суперадминов бота.
+type ChatRole struct {
+	ID         int64     `gorm:"primaryKey"`
+	TelegramID int64     `gorm:"not null"`
+	ChatID     int64     `gorm:"not null"`
+	Role       string    `gorm:"not null;default:user"`   // user, moder, admin
+	Source     string    `gorm:"not null;default:manual"` // manual, telegram
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// RoleSyncOptOut - пользователь, чью роль в чате синхронизация с Telegram не трогает
+type RoleSyncOptOut struct {
+	ID         int64     `gorm:"primaryKey"`
+	ChatID     int64     `gorm:"not null"`
+	TelegramID int64     `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// ChatAdmin - админ чата в Telegram и роль бота, которая ему положена по его правам
+type ChatAdmin struct {
+	TelegramID int64
+	Name       string
+	Role       string
+}
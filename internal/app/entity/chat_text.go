@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	ChatTextRules = "rules" // правила чата
+	ChatTextFaq   = "faq"   // ответы на частые вопросы
+)
+
+const (
+	MediaPhoto     = "photo"
+	MediaVideo     = "video"
+	MediaDocument  = "document"
+	MediaAnimation = "animation"
+)
+
+// ChatText - правила или FAQ чата вместе с разметкой Telegram
+type ChatText struct {
+	ID          int64     `gorm:"primaryKey"`
+	ChatID      int64     `gorm:"not null"`
+	Kind        string    `gorm:"not null"` // rules, faq
+	Text        string    `gorm:"not null;default:''"`
+	Entities    *string   `gorm:"type:jsonb;default:null"` // []models.MessageEntity в JSON
+	MediaType   *string   `gorm:"default:null"`            // photo, video, document, animation
+	MediaFileID *string   `gorm:"default:null"`
+	UpdatedBy   *int64    `gorm:"default:null"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
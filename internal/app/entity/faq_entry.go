@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// FaqEntry - один вопрос из базы знаний чата. ChatID == nil - вопрос общий для всех чатов.
+type FaqEntry struct {
+	ID             int64     `gorm:"primaryKey"`
+	ChatID         *int64    `gorm:"default:null"`
+	Question       string    `gorm:"not null"`
+	Answer         string    `gorm:"not null"`
+	AnswerEntities *string   `gorm:"type:jsonb;default:null"` // []models.MessageEntity в JSON
+	MediaType      *string   `gorm:"default:null"`            // photo, video, document, animation
+	MediaFileID    *string   `gorm:"default:null"`
+	Tags           string    `gorm:"not null;default:''"` // категории через запятую
+	Keywords       string    `gorm:"not null;default:''"` // слова для поиска через запятую
+	Patterns       string    `gorm:"not null;default:''"` // регулярки для автоответов, по одной на строку
+	CreatedBy      *int64    `gorm:"default:null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// IsGlobal - вопрос виден во всех чатах
+func (e *FaqEntry) IsGlobal() bool {
+	return e.ChatID == nil
+}
+
+func (e *FaqEntry) TagList() []string {
+	return splitList(e.Tags)
+}
+
+func (e *FaqEntry) KeywordList() []string {
+	return splitList(e.Keywords)
+}
+
+func (e *FaqEntry) PatternList() []string {
+	var patterns []string
+	for _, pattern := range strings.Split(e.Patterns, "\n") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// FaqRejection - модератор отметил, что автоответ на это сообщение был не в тему
+type FaqRejection struct {
+	ID         int64     `gorm:"primaryKey"`
+	ChatID     int64     `gorm:"not null"`
+	EntryID    int64     `gorm:"not null"`
+	Text       string    `gorm:"not null"`
+	RejectedBy int64     `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// FaqMatch - найденный вопрос и насколько он подходит, от 0 до 1
+type FaqMatch struct {
+	Entry *FaqEntry
+	Score float64
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
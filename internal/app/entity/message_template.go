@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// MessageTemplate - текст сообщения Морти, переопределённый в чате
+type MessageTemplate struct {
+	ID        int64     `gorm:"primaryKey"`
+	ChatID    int64     `gorm:"not null"`
+	Name      string    `gorm:"not null"`
+	Body      string    `gorm:"not null"` // text/template, результат в MarkdownV2
+	UpdatedBy *int64    `gorm:"default:null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+type Mute struct {
+	ID          int64      `gorm:"primaryKey"`
+	ChatID      int64      `gorm:"not null"`
+	TelegramID  int64      `gorm:"not null"` // кого замутили
+	ModeratorID int64      `gorm:"not null"` // кто замутил
+	Reason      *string    `gorm:"default:null"`
+	Until       *time.Time `gorm:"default:null"` // nil - бессрочно
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
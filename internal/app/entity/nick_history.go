@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	NickSourceSelfVerified = "self_verified" // сам прислал ник и прошёл проверку
+	NickSourceManual       = "manual"        // выдан через /save
+	NickSourceLegacy       = "legacy"        // сохранён до появления истории
+)
+
+// NickHistory - запись о том, какой ник и кем был выдан пользователю
+type NickHistory struct {
+	ID         int64     `gorm:"primaryKey"`
+	TelegramID int64     `gorm:"not null"`
+	SchoolName *string   `gorm:"default:null"` // nil - одобрен без школьного ника
+	Source     string    `gorm:"not null"`     // self_verified, manual, legacy
+	SetBy      *int64    `gorm:"default:null"` // кто выдал ник через /save
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (NickHistory) TableName() string {
+	return "user_nick_history"
+}
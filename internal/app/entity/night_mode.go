@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+const (
+	NightRestrictionMedia = "media" // запрещаем медиа, текст остаётся
+	NightRestrictionAll   = "all"   // запрещаем любые сообщения
+)
+
+type NightMode struct {
+	ID               int64     `gorm:"primaryKey"`
+	ChatID           int64     `gorm:"uniqueIndex;not null"`
+	StartMinute      int       `gorm:"not null"` // минуты от полуночи по Москве
+	EndMinute        int       `gorm:"not null"`
+	Restriction      string    `gorm:"not null;default:media"` // media, all
+	Enabled          bool      `gorm:"not null;default:true"`
+	Active           bool      `gorm:"not null;default:false"`  // ограничения сейчас применены к чату
+	SavedPermissions *string   `gorm:"type:jsonb;default:null"` // права чата до включения ночного режима
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+// Capability - именованное право на действие в чате
+type Capability string
+
+const (
+	CapActivateChat      Capability = "activate_chat"      // /morty_come_here
+	CapSetTopic          Capability = "set_topic"          // /morty_id_topic_here
+	CapEditRules         Capability = "edit_rules"         // /morty_rules
+	CapEditFaq           Capability = "edit_faq"           // /morty_faq, /faq_add
+	CapTuneFaq           Capability = "tune_faq"           // кнопка "Не то" под автоответом
+	CapManageChat        Capability = "manage_chat"        // медленный и ночной режимы
+	CapMute              Capability = "mute"               // /mute, /unmute
+	CapBan               Capability = "ban"                // /ban, /unban
+	CapViewMutes         Capability = "view_mutes"         // /muted
+	CapViewStats         Capability = "view_stats"         // /morty_stats
+	CapBypassSlowMode    Capability = "bypass_slowmode"    // медленный режим не действует
+	CapSaveUser          Capability = "save_user"          // /save
+	CapWhois             Capability = "whois"              // /whois
+	CapManageRoles       Capability = "manage_roles"       // /role
+	CapManagePermissions Capability = "manage_permissions" // /morty_perm
+)
+
+// ChatPermission - переопределение права роли в конкретном чате
+type ChatPermission struct {
+	ID         int64      `gorm:"primaryKey"`
+	ChatID     int64      `gorm:"not null"`
+	Role       string     `gorm:"not null"`
+	Capability Capability `gorm:"not null"`
+	Allowed    bool       `gorm:"not null"`
+}
@@ -2,10 +2,26 @@ package entity
 
 import "time"
 
+const (
+	RoleUser       = "user"
+	RoleModer      = "moder"
+	RoleAdmin      = "admin"
+	RoleSuperadmin = "superadmin"
+)
+
 type User struct {
 	ID         int64     `gorm:"primaryKey"`
 	TelegramID int64     `gorm:"uniqueIndex;not null"`
-	SchoolName string    `gorm:"uniqueIndex;not null"`
-	Role       string    `gorm:"not null;default:user"` // user, moderator, admin, superadmin
+	SchoolName *string   `gorm:"uniqueIndex;default:null"` // nil - одобрен вручную без школьного ника
+	Username   *string   `gorm:"default:null"`             // последний username в Telegram, в нижнем регистре
+	Role       string    `gorm:"not null;default:user"`    // user, moder, admin, superadmin
 	CreatedAt  time.Time `gorm:"autoCreateTime"`
 }
+
+// Nick возвращает школьный ник или пустую строку, если его нет
+func (u *User) Nick() string {
+	if u.SchoolName == nil {
+		return ""
+	}
+	return *u.SchoolName
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChatMemberRepository interface {
+	Get(ctx context.Context, chatID, telegramID int64) (*entity.ChatMember, error)
+	Upsert(ctx context.Context, member *entity.ChatMember) error
+	UpdateStatus(ctx context.Context, chatID, telegramID int64, updates map[string]interface{}) error
+	GetByChatID(ctx context.Context, chatID int64, statuses []string) ([]*entity.ChatMember, error)
+	GetActiveByTelegramID(ctx context.Context, telegramID int64) ([]*entity.ChatMember, error)
+	CountByStatus(ctx context.Context, chatID int64) (map[string]int64, error)
+	CountJoinedSince(ctx context.Context, chatID int64, since time.Time) (int64, error)
+	CountLeftSince(ctx context.Context, chatID int64, since time.Time) (int64, error)
+}
+
+type PostgresChatMemberRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresChatMemberRepository(db *gorm.DB) *PostgresChatMemberRepository {
+	return &PostgresChatMemberRepository{DB: db}
+}
+
+func (r *PostgresChatMemberRepository) Get(ctx context.Context, chatID, telegramID int64) (*entity.ChatMember, error) {
+	var member entity.ChatMember
+	if err := r.DB.WithContext(ctx).
+		Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).
+		First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Upsert записывает новый заход в чат, повторный заход перезаписывает старый
+func (r *PostgresChatMemberRepository) Upsert(ctx context.Context, member *entity.ChatMember) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}, {Name: "telegram_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "joined_at", "verified_at", "left_at"}),
+		}).
+		Create(member).Error
+}
+
+func (r *PostgresChatMemberRepository) UpdateStatus(ctx context.Context, chatID, telegramID int64, updates map[string]interface{}) error {
+	return r.DB.WithContext(ctx).
+		Model(&entity.ChatMember{}).
+		Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).
+		Updates(updates).Error
+}
+
+func (r *PostgresChatMemberRepository) GetByChatID(ctx context.Context, chatID int64, statuses []string) ([]*entity.ChatMember, error) {
+	var members []*entity.ChatMember
+	err := r.DB.WithContext(ctx).
+		Where("chat_id = ? AND status IN ?", chatID, statuses).
+		Order("joined_at").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat members: %w", err)
+	}
+	return members, nil
+}
+
+func (r *PostgresChatMemberRepository) GetActiveByTelegramID(ctx context.Context, telegramID int64) ([]*entity.ChatMember, error) {
+	var members []*entity.ChatMember
+	err := r.DB.WithContext(ctx).
+		Where("telegram_id = ? AND status IN ?", telegramID, []string{entity.MemberStatusPending, entity.MemberStatusMember}).
+		Order("joined_at").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user chats: %w", err)
+	}
+	return members, nil
+}
+
+func (r *PostgresChatMemberRepository) CountByStatus(ctx context.Context, chatID int64) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.DB.WithContext(ctx).
+		Model(&entity.ChatMember{}).
+		Select("status, COUNT(*) AS count").
+		Where("chat_id = ?", chatID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chat members: %w", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *PostgresChatMemberRepository) CountJoinedSince(ctx context.Context, chatID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&entity.ChatMember{}).
+		Where("chat_id = ? AND joined_at >= ?", chatID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *PostgresChatMemberRepository) CountLeftSince(ctx context.Context, chatID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&entity.ChatMember{}).
+		Where("chat_id = ? AND left_at >= ?", chatID, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChatPermissionRepository interface {
+	GetByChatID(ctx context.Context, chatID int64) ([]*entity.ChatPermission, error)
+	GetByChatIDAndRole(ctx context.Context, chatID int64, role string) ([]*entity.ChatPermission, error)
+	Upsert(ctx context.Context, permission *entity.ChatPermission) error
+	DeleteByRole(ctx context.Context, chatID int64, role string) error
+}
+
+type PostgresChatPermissionRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresChatPermissionRepository(db *gorm.DB) *PostgresChatPermissionRepository {
+	return &PostgresChatPermissionRepository{DB: db}
+}
+
+func (r *PostgresChatPermissionRepository) GetByChatID(ctx context.Context, chatID int64) ([]*entity.ChatPermission, error) {
+	var permissions []*entity.ChatPermission
+	err := r.DB.WithContext(ctx).Where("chat_id = ?", chatID).Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+func (r *PostgresChatPermissionRepository) GetByChatIDAndRole(ctx context.Context, chatID int64, role string) ([]*entity.ChatPermission, error) {
+	var permissions []*entity.ChatPermission
+	err := r.DB.WithContext(ctx).Where("chat_id = ? AND role = ?", chatID, role).Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+func (r *PostgresChatPermissionRepository) Upsert(ctx context.Context, permission *entity.ChatPermission) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}, {Name: "role"}, {Name: "capability"}},
+			DoUpdates: clause.AssignmentColumns([]string{"allowed"}),
+		}).
+		Create(permission).Error
+}
+
+func (r *PostgresChatPermissionRepository) DeleteByRole(ctx context.Context, chatID int64, role string) error {
+	return r.DB.WithContext(ctx).
+		Where("chat_id = ? AND role = ?", chatID, role).
+		Delete(&entity.ChatPermission{}).Error
+}
@@ -11,8 +11,9 @@ import (
 type ChatRepository interface {
 	Create(ctx context.Context, chat *entity.Chat) error
 	UpdateThreadID(ctx context.Context, chatID int64, threadID int) error
-	UpdateRulesLink(ctx context.Context, chatID int64, rulesLink string) error
-	UpdateFaqLink(ctx context.Context, chatID int64, faqLink string) error
+	UpdateSlowModeDelay(ctx context.Context, chatID int64, delay int) error
+	UpdateAutoFaqThreshold(ctx context.Context, chatID int64, threshold float64) error
+	UpdateLanguage(ctx context.Context, chatID int64, language string) error
 	GetByChatID(ctx context.Context, chatID int64) (*entity.Chat, error)
 	GetAllChats(ctx context.Context) ([]*entity.Chat, error)
 }
@@ -36,18 +37,25 @@ func (r *PostgresChatRepository) UpdateThreadID(ctx context.Context, chatID int6
 		Update("thread_id", threadID).Error
 }
 
-func (r *PostgresChatRepository) UpdateRulesLink(ctx context.Context, chatID int64, rulesLink string) error {
+func (r *PostgresChatRepository) UpdateSlowModeDelay(ctx context.Context, chatID int64, delay int) error {
 	return r.DB.WithContext(ctx).
 		Model(&entity.Chat{}).
 		Where("chat_id = ?", chatID).
-		Update("rules_link", rulesLink).Error
+		Update("slow_mode_delay", delay).Error
 }
 
-func (r *PostgresChatRepository) UpdateFaqLink(ctx context.Context, chatID int64, faqLink string) error {
+func (r *PostgresChatRepository) UpdateAutoFaqThreshold(ctx context.Context, chatID int64, threshold float64) error {
 	return r.DB.WithContext(ctx).
 		Model(&entity.Chat{}).
 		Where("chat_id = ?", chatID).
-		Update("faq_link", faqLink).Error
+		Update("auto_faq_threshold", threshold).Error
+}
+
+func (r *PostgresChatRepository) UpdateLanguage(ctx context.Context, chatID int64, language string) error {
+	return r.DB.WithContext(ctx).
+		Model(&entity.Chat{}).
+		Where("chat_id = ?", chatID).
+		Update("language", language).Error
 }
 
 func (r *PostgresChatRepository) GetByChatID(ctx context.Context, chatID int64) (*entity.Chat, error) {
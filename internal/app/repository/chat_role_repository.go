@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChatRoleRepository interface {
+	Get(ctx context.Context, chatID, telegramID int64) (*entity.ChatRole, error)
+	GetByChatID(ctx context.Context, chatID int64) ([]*entity.ChatRole, error)
+	Upsert(ctx context.Context, chatRole *entity.ChatRole) error
+	Delete(ctx context.Context, chatID, telegramID int64) error
+}
+
+type PostgresChatRoleRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresChatRoleRepository(db *gorm.DB) *PostgresChatRoleRepository {
+	return &PostgresChatRoleRepository{DB: db}
+}
+
+func (r *PostgresChatRoleRepository) Get(ctx context.Context, chatID, telegramID int64) (*entity.ChatRole, error) {
+	var chatRole entity.ChatRole
+	if err := r.DB.WithContext(ctx).
+		Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).
+		First(&chatRole).Error; err != nil {
+		return nil, err
+	}
+	return &chatRole, nil
+}
+
+func (r *PostgresChatRoleRepository) GetByChatID(ctx context.Context, chatID int64) ([]*entity.ChatRole, error) {
+	var chatRoles []*entity.ChatRole
+	err := r.DB.WithContext(ctx).Where("chat_id = ?", chatID).Find(&chatRoles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat roles: %w", err)
+	}
+	return chatRoles, nil
+}
+
+func (r *PostgresChatRoleRepository) Upsert(ctx context.Context, chatRole *entity.ChatRole) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "telegram_id"}, {Name: "chat_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "source"}),
+		}).
+		Create(chatRole).Error
+}
+
+func (r *PostgresChatRoleRepository) Delete(ctx context.Context, chatID, telegramID int64) error {
+	return r.DB.WithContext(ctx).
+		Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).
+		Delete(&entity.ChatRole{}).Error
+}
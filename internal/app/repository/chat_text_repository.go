@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChatTextRepository interface {
+	Get(ctx context.Context, chatID int64, kind string) (*entity.ChatText, error)
+	GetAll(ctx context.Context) ([]*entity.ChatText, error)
+	Upsert(ctx context.Context, text *entity.ChatText) error
+}
+
+type PostgresChatTextRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresChatTextRepository(db *gorm.DB) *PostgresChatTextRepository {
+	return &PostgresChatTextRepository{DB: db}
+}
+
+func (r *PostgresChatTextRepository) Get(ctx context.Context, chatID int64, kind string) (*entity.ChatText, error) {
+	var text entity.ChatText
+	if err := r.DB.WithContext(ctx).
+		Where("chat_id = ? AND kind = ?", chatID, kind).
+		First(&text).Error; err != nil {
+		return nil, err
+	}
+	return &text, nil
+}
+
+func (r *PostgresChatTextRepository) GetAll(ctx context.Context) ([]*entity.ChatText, error) {
+	var texts []*entity.ChatText
+	if err := r.DB.WithContext(ctx).Find(&texts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat texts: %w", err)
+	}
+	return texts, nil
+}
+
+// Upsert перезаписывает текст целиком, вместе с разметкой и медиа
+func (r *PostgresChatTextRepository) Upsert(ctx context.Context, text *entity.ChatText) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}, {Name: "kind"}},
+			DoUpdates: clause.AssignmentColumns([]string{"text", "entities", "media_type", "media_file_id", "updated_by", "updated_at"}),
+		}).
+		Create(text).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+)
+
+type FaqEntryRepository interface {
+	Create(ctx context.Context, entry *entity.FaqEntry) error
+	Update(ctx context.Context, entry *entity.FaqEntry) error
+	UpdatePatterns(ctx context.Context, id int64, patterns string) error
+	Delete(ctx context.Context, id int64) error
+	GetByID(ctx context.Context, id int64) (*entity.FaqEntry, error)
+	GetForChat(ctx context.Context, chatID int64) ([]*entity.FaqEntry, error)
+}
+
+type PostgresFaqEntryRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresFaqEntryRepository(db *gorm.DB) *PostgresFaqEntryRepository {
+	return &PostgresFaqEntryRepository{DB: db}
+}
+
+func (r *PostgresFaqEntryRepository) Create(ctx context.Context, entry *entity.FaqEntry) error {
+	return r.DB.WithContext(ctx).Create(entry).Error
+}
+
+func (r *PostgresFaqEntryRepository) Update(ctx context.Context, entry *entity.FaqEntry) error {
+	return r.DB.WithContext(ctx).Save(entry).Error
+}
+
+func (r *PostgresFaqEntryRepository) UpdatePatterns(ctx context.Context, id int64, patterns string) error {
+	return r.DB.WithContext(ctx).
+		Model(&entity.FaqEntry{}).
+		Where("id = ?", id).
+		Update("patterns", patterns).Error
+}
+
+func (r *PostgresFaqEntryRepository) Delete(ctx context.Context, id int64) error {
+	return r.DB.WithContext(ctx).Delete(&entity.FaqEntry{}, id).Error
+}
+
+func (r *PostgresFaqEntryRepository) GetByID(ctx context.Context, id int64) (*entity.FaqEntry, error) {
+	var entry entity.FaqEntry
+	if err := r.DB.WithContext(ctx).First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetForChat возвращает вопросы чата вместе с общими
+func (r *PostgresFaqEntryRepository) GetForChat(ctx context.Context, chatID int64) ([]*entity.FaqEntry, error) {
+	var entries []*entity.FaqEntry
+	err := r.DB.WithContext(ctx).
+		Where("chat_id = ? OR chat_id IS NULL", chatID).
+		Order("id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch faq entries: %w", err)
+	}
+	return entries, nil
+}
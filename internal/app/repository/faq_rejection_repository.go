@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+)
+
+type FaqRejectionRepository interface {
+	Create(ctx context.Context, rejection *entity.FaqRejection) error
+	GetByChatID(ctx context.Context, chatID int64) ([]*entity.FaqRejection, error)
+}
+
+type PostgresFaqRejectionRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresFaqRejectionRepository(db *gorm.DB) *PostgresFaqRejectionRepository {
+	return &PostgresFaqRejectionRepository{DB: db}
+}
+
+func (r *PostgresFaqRejectionRepository) Create(ctx context.Context, rejection *entity.FaqRejection) error {
+	return r.DB.WithContext(ctx).Create(rejection).Error
+}
+
+func (r *PostgresFaqRejectionRepository) GetByChatID(ctx context.Context, chatID int64) ([]*entity.FaqRejection, error) {
+	var rejections []*entity.FaqRejection
+	if err := r.DB.WithContext(ctx).Where("chat_id = ?", chatID).Find(&rejections).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch faq rejections: %w", err)
+	}
+	return rejections, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageTemplateRepository interface {
+	GetByChatID(ctx context.Context, chatID int64) ([]*entity.MessageTemplate, error)
+	Upsert(ctx context.Context, tmpl *entity.MessageTemplate) error
+	Delete(ctx context.Context, chatID int64, name string) error
+}
+
+type PostgresMessageTemplateRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresMessageTemplateRepository(db *gorm.DB) *PostgresMessageTemplateRepository {
+	return &PostgresMessageTemplateRepository{DB: db}
+}
+
+func (r *PostgresMessageTemplateRepository) GetByChatID(ctx context.Context, chatID int64) ([]*entity.MessageTemplate, error) {
+	var templates []*entity.MessageTemplate
+	if err := r.DB.WithContext(ctx).Where("chat_id = ?", chatID).Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch message templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *PostgresMessageTemplateRepository) Upsert(ctx context.Context, tmpl *entity.MessageTemplate) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}, {Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"body", "updated_by", "updated_at"}),
+		}).
+		Create(tmpl).Error
+}
+
+func (r *PostgresMessageTemplateRepository) Delete(ctx context.Context, chatID int64, name string) error {
+	return r.DB.WithContext(ctx).
+		Where("chat_id = ? AND name = ?", chatID, name).
+		Delete(&entity.MessageTemplate{}).Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MuteRepository interface {
+	Upsert(ctx context.Context, mute *entity.Mute) error
+	Delete(ctx context.Context, chatID, telegramID int64) (bool, error)
+	GetActiveByChatID(ctx context.Context, chatID int64, now time.Time) ([]*entity.Mute, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type PostgresMuteRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresMuteRepository(db *gorm.DB) *PostgresMuteRepository {
+	return &PostgresMuteRepository{DB: db}
+}
+
+// Upsert сохраняет мут, повторный мут того же человека в чате перезаписывает старый
+func (r *PostgresMuteRepository) Upsert(ctx context.Context, mute *entity.Mute) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}, {Name: "telegram_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"moderator_id", "reason", "until", "created_at"}),
+		}).
+		Create(mute).Error
+}
+
+func (r *PostgresMuteRepository) Delete(ctx context.Context, chatID, telegramID int64) (bool, error) {
+	result := r.DB.WithContext(ctx).
+		Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).
+		Delete(&entity.Mute{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PostgresMuteRepository) GetActiveByChatID(ctx context.Context, chatID int64, now time.Time) ([]*entity.Mute, error) {
+	var mutes []*entity.Mute
+	err := r.DB.WithContext(ctx).
+		Where("chat_id = ? AND (until IS NULL OR until > ?)", chatID, now).
+		Order("created_at").
+		Find(&mutes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch mutes: %w", err)
+	}
+	return mutes, nil
+}
+
+func (r *PostgresMuteRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).
+		Where("until IS NOT NULL AND until <= ?", now).
+		Delete(&entity.Mute{})
+	return result.RowsAffected, result.Error
+}
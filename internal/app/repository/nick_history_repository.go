@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+)
+
+type NickHistoryRepository interface {
+	GetByTelegramID(ctx context.Context, telegramID int64) ([]*entity.NickHistory, error)
+	GetLatestBySchoolName(ctx context.Context, schoolName string) (*entity.NickHistory, error)
+}
+
+type PostgresNickHistoryRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresNickHistoryRepository(db *gorm.DB) *PostgresNickHistoryRepository {
+	return &PostgresNickHistoryRepository{DB: db}
+}
+
+// GetByTelegramID возвращает историю ников, свежие записи первыми
+func (r *PostgresNickHistoryRepository) GetByTelegramID(ctx context.Context, telegramID int64) ([]*entity.NickHistory, error) {
+	var history []*entity.NickHistory
+	err := r.DB.WithContext(ctx).
+		Where("telegram_id = ?", telegramID).
+		Order("created_at DESC, id DESC").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nick history: %w", err)
+	}
+	return history, nil
+}
+
+// GetLatestBySchoolName возвращает последнюю запись, в которой встречался ник
+func (r *PostgresNickHistoryRepository) GetLatestBySchoolName(ctx context.Context, schoolName string) (*entity.NickHistory, error) {
+	var record entity.NickHistory
+	err := r.DB.WithContext(ctx).
+		Where("school_name = ?", schoolName).
+		Order("created_at DESC, id DESC").
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NightModeRepository interface {
+	Upsert(ctx context.Context, nightMode *entity.NightMode) error
+	GetByChatID(ctx context.Context, chatID int64) (*entity.NightMode, error)
+	GetScheduled(ctx context.Context) ([]*entity.NightMode, error)
+	Disable(ctx context.Context, chatID int64) error
+	UpdateActive(ctx context.Context, chatID int64, active bool, savedPermissions *string) error
+}
+
+type PostgresNightModeRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresNightModeRepository(db *gorm.DB) *PostgresNightModeRepository {
+	return &PostgresNightModeRepository{DB: db}
+}
+
+// Upsert создаёт расписание ночного режима или обновляет существующее.
+// Флаг active и сохранённые права не трогаем - ими управляет планировщик.
+func (r *PostgresNightModeRepository) Upsert(ctx context.Context, nightMode *entity.NightMode) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"start_minute", "end_minute", "restriction", "enabled", "updated_at"}),
+		}).
+		Omit("active", "saved_permissions").
+		Create(nightMode).Error
+}
+
+func (r *PostgresNightModeRepository) GetByChatID(ctx context.Context, chatID int64) (*entity.NightMode, error) {
+	var nightMode entity.NightMode
+	if err := r.DB.WithContext(ctx).Where("chat_id = ?", chatID).First(&nightMode).Error; err != nil {
+		return nil, err
+	}
+	return &nightMode, nil
+}
+
+// GetScheduled возвращает включённые расписания и те, что ещё не сняли ограничения.
+func (r *PostgresNightModeRepository) GetScheduled(ctx context.Context) ([]*entity.NightMode, error) {
+	var nightModes []*entity.NightMode
+	err := r.DB.WithContext(ctx).Where("enabled = ? OR active = ?", true, true).Find(&nightModes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch night modes: %w", err)
+	}
+	return nightModes, nil
+}
+
+func (r *PostgresNightModeRepository) Disable(ctx context.Context, chatID int64) error {
+	return r.DB.WithContext(ctx).
+		Model(&entity.NightMode{}).
+		Where("chat_id = ?", chatID).
+		Update("enabled", false).Error
+}
+
+func (r *PostgresNightModeRepository) UpdateActive(ctx context.Context, chatID int64, active bool, savedPermissions *string) error {
+	return r.DB.WithContext(ctx).
+		Model(&entity.NightMode{}).
+		Where("chat_id = ?", chatID).
+		Updates(map[string]interface{}{
+			"active":            active,
+			"saved_permissions": savedPermissions,
+		}).Error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleSyncOptOutRepository interface {
+	GetByChatID(ctx context.Context, chatID int64) ([]*entity.RoleSyncOptOut, error)
+	Create(ctx context.Context, optOut *entity.RoleSyncOptOut) error
+	Delete(ctx context.Context, chatID, telegramID int64) error
+}
+
+type PostgresRoleSyncOptOutRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresRoleSyncOptOutRepository(db *gorm.DB) *PostgresRoleSyncOptOutRepository {
+	return &PostgresRoleSyncOptOutRepository{DB: db}
+}
+
+func (r *PostgresRoleSyncOptOutRepository) GetByChatID(ctx context.Context, chatID int64) ([]*entity.RoleSyncOptOut, error) {
+	var optOuts []*entity.RoleSyncOptOut
+	err := r.DB.WithContext(ctx).Where("chat_id = ?", chatID).Find(&optOuts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch role sync opt-outs: %w", err)
+	}
+	return optOuts, nil
+}
+
+func (r *PostgresRoleSyncOptOutRepository) Create(ctx context.Context, optOut *entity.RoleSyncOptOut) error {
+	return r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(optOut).Error
+}
+
+func (r *PostgresRoleSyncOptOutRepository) Delete(ctx context.Context, chatID, telegramID int64) error {
+	return r.DB.WithContext(ctx).
+		Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).
+		Delete(&entity.RoleSyncOptOut{}).Error
+}
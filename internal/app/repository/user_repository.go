@@ -7,14 +7,23 @@ import (
 	"morty-smith-34-c/internal/app/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrLastSuperadmin - снять роль с последнего суперадмина нельзя
+var ErrLastSuperadmin = errors.New("last superadmin can't be demoted")
+
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
+	CreateWithNick(ctx context.Context, user *entity.User, record *entity.NickHistory) error
 	GetByTelegramID(ctx context.Context, telegramID int64) (*entity.User, error)
+	GetBySchoolName(ctx context.Context, schoolName string) (*entity.User, error)
+	GetByUsername(ctx context.Context, username string) (*entity.User, error)
+	UpdateUsername(ctx context.Context, telegramID int64, username *string) error
 	UpdateRole(ctx context.Context, telegramID int64, role string) error
+	ChangeRole(ctx context.Context, chatID, telegramID int64, role string) error
 	Exists(ctx context.Context, telegramID int64) (bool, error)
-	UpdateSchoolNick(ctx context.Context, telegramID int64, nick string) (bool, error)
+	UpdateSchoolNick(ctx context.Context, telegramID int64, nick *string, record *entity.NickHistory) (bool, error)
 }
 
 type PostgresUserRepository struct {
@@ -30,6 +39,16 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *entity.User)
 	return r.DB.WithContext(ctx).Create(user).Error
 }
 
+// CreateWithNick добавляет пользователя вместе с первой записью в истории ников
+func (r *PostgresUserRepository) CreateWithNick(ctx context.Context, user *entity.User, record *entity.NickHistory) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+}
+
 func (r *PostgresUserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*entity.User, error) {
 	var user entity.User
 	if err := r.DB.WithContext(ctx).Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
@@ -38,6 +57,39 @@ func (r *PostgresUserRepository) GetByTelegramID(ctx context.Context, telegramID
 	return &user, nil
 }
 
+func (r *PostgresUserRepository) GetBySchoolName(ctx context.Context, schoolName string) (*entity.User, error) {
+	var user entity.User
+	if err := r.DB.WithContext(ctx).Where("school_name = ?", schoolName).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	var user entity.User
+	if err := r.DB.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUsername запоминает username пользователя. Username в Telegram уникален,
+// поэтому у прежнего владельца он снимается.
+func (r *PostgresUserRepository) UpdateUsername(ctx context.Context, telegramID int64, username *string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if username != nil {
+			if err := tx.Model(&entity.User{}).
+				Where("username = ? AND telegram_id <> ?", *username, telegramID).
+				Update("username", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entity.User{}).
+			Where("telegram_id = ?", telegramID).
+			Update("username", username).Error
+	})
+}
+
 func (r *PostgresUserRepository) UpdateRole(ctx context.Context, telegramID int64, role string) error {
 	return r.DB.WithContext(ctx).
 		Model(&entity.User{}).
@@ -45,6 +97,49 @@ func (r *PostgresUserRepository) UpdateRole(ctx context.Context, telegramID int6
 		Update("role", role).Error
 }
 
+// ChangeRole выдаёт роль одной транзакцией: superadmin - глобально в users,
+// остальные - в chat_roles чата chatID, user снимает роль в чате.
+// Суперадмин при этом теряет глобальную роль, если он не последний.
+func (r *PostgresUserRepository) ChangeRole(ctx context.Context, chatID, telegramID int64, role string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if role == entity.RoleSuperadmin {
+			return tx.Model(&entity.User{}).Where("telegram_id = ?", telegramID).Update("role", role).Error
+		}
+
+		// Блокируем всех суперадминов, чтобы два встречных понижения не оставили ни одного
+		var superadmins []int64
+		if err := tx.Model(&entity.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ?", entity.RoleSuperadmin).
+			Pluck("telegram_id", &superadmins).Error; err != nil {
+			return err
+		}
+		for _, id := range superadmins {
+			if id != telegramID {
+				continue
+			}
+			if len(superadmins) <= 1 {
+				return ErrLastSuperadmin
+			}
+			if err := tx.Model(&entity.User{}).Where("telegram_id = ?", telegramID).Update("role", entity.RoleUser).Error; err != nil {
+				return err
+			}
+		}
+
+		if role == entity.RoleUser {
+			return tx.Where("chat_id = ? AND telegram_id = ?", chatID, telegramID).Delete(&entity.ChatRole{}).Error
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "telegram_id"}, {Name: "chat_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "source"}),
+		}).Create(&entity.ChatRole{
+			TelegramID: telegramID,
+			ChatID:     chatID,
+			Role:       role,
+			Source:     entity.RoleSourceManual,
+		}).Error
+	})
+}
+
 func (r *PostgresUserRepository) Exists(ctx context.Context, telegramID int64) (bool, error) {
 	var count int64
 	if err := r.DB.WithContext(ctx).
@@ -56,30 +151,44 @@ func (r *PostgresUserRepository) Exists(ctx context.Context, telegramID int64) (
 	return count > 0, nil
 }
 
-func (r *PostgresUserRepository) UpdateSchoolNick(ctx context.Context, telegramID int64, nick string) (bool, error) {
-	var user struct {
-		SchoolName string
-	}
+// UpdateSchoolNick меняет ник, nick = nil - одобрен без школьного ника.
+// Смена и запись в истории ников сохраняются вместе. Возвращает false, если ник не поменялся.
+func (r *PostgresUserRepository) UpdateSchoolNick(ctx context.Context, telegramID int64, nick *string, record *entity.NickHistory) (bool, error) {
+	changed := false
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user struct {
+			SchoolName *string
+		}
 
-	if err := r.DB.WithContext(ctx).Model(&entity.User{}).Select("school_name").Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, fmt.Errorf("user with telegram_id %d not found", telegramID)
+		// Блокируем строку, чтобы две одновременные смены не записали историю вразнобой
+		if err := tx.Model(&entity.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).Select("school_name").Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("user with telegram_id %d not found", telegramID)
+			}
+			return err
 		}
-		return false, err
-	}
 
-	if user.SchoolName == nick {
-		return false, nil
-	}
+		if (user.SchoolName == nil && nick == nil) || (user.SchoolName != nil && nick != nil && *user.SchoolName == *nick) {
+			return nil
+		}
 
-	result := r.DB.WithContext(ctx).Model(&entity.User{}).Where("telegram_id = ?", telegramID).Update("school_name", nick)
-	if result.Error != nil {
-		return false, result.Error
-	}
+		result := tx.Model(&entity.User{}).Where("telegram_id = ?", telegramID).Update("school_name", nick)
+		if result.Error != nil {
+			return result.Error
+		}
 
-	if result.RowsAffected == 0 {
-		return false, fmt.Errorf("failed to update school_name for telegram_id %d", telegramID)
-	}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("failed to update school_name for telegram_id %d", telegramID)
+		}
 
-	return true, nil
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
 }
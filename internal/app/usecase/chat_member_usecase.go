@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ChatMemberUseCase struct {
+	ChatMemberRepo repository.ChatMemberRepository
+}
+
+func NewChatMemberUseCase(repo repository.ChatMemberRepository) *ChatMemberUseCase {
+	return &ChatMemberUseCase{
+		ChatMemberRepo: repo,
+	}
+}
+
+// Joined отмечает заход в чат. verified - пользователь уже проверен раньше.
+// Если он и так числится в чате, ничего не меняется: о заходе могут сообщить
+// и сервисное сообщение, и обновление chat_member.
+func (u *ChatMemberUseCase) Joined(ctx context.Context, chatID, telegramID int64, verified bool) error {
+	existing, err := u.ChatMemberRepo.Get(ctx, chatID, telegramID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && existing.IsActive() {
+		if verified && existing.Status == entity.MemberStatusPending {
+			return u.Verified(ctx, chatID, telegramID)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	member := &entity.ChatMember{
+		ChatID:     chatID,
+		TelegramID: telegramID,
+		Status:     entity.MemberStatusPending,
+		JoinedAt:   now,
+	}
+	if verified {
+		member.Status = entity.MemberStatusMember
+		member.VerifiedAt = &now
+	}
+	return u.ChatMemberRepo.Upsert(ctx, member)
+}
+
+// Verified отмечает, что участник прошёл проверку ника
+func (u *ChatMemberUseCase) Verified(ctx context.Context, chatID, telegramID int64) error {
+	existing, err := u.ChatMemberRepo.Get(ctx, chatID, telegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return u.Joined(ctx, chatID, telegramID, true)
+		}
+		return err
+	}
+	if existing.Status == entity.MemberStatusMember {
+		return nil
+	}
+	return u.ChatMemberRepo.UpdateStatus(ctx, chatID, telegramID, map[string]interface{}{
+		"status":      entity.MemberStatusMember,
+		"verified_at": time.Now(),
+		"left_at":     nil,
+	})
+}
+
+// Left отмечает выход из чата со статусом left, kicked или banned
+func (u *ChatMemberUseCase) Left(ctx context.Context, chatID, telegramID int64, status string) error {
+	existing, err := u.ChatMemberRepo.Get(ctx, chatID, telegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Зашёл до того, как мы начали следить, запомним хотя бы уход
+			now := time.Now()
+			return u.ChatMemberRepo.Upsert(ctx, &entity.ChatMember{
+				ChatID:     chatID,
+				TelegramID: telegramID,
+				Status:     status,
+				JoinedAt:   now,
+				LeftAt:     &now,
+			})
+		}
+		return err
+	}
+	// Бан сильнее ухода: после бана Telegram может прислать ещё и left
+	if !existing.IsActive() && (existing.Status == entity.MemberStatusBanned || existing.Status == status) {
+		return nil
+	}
+	updates := map[string]interface{}{"status": status}
+	if existing.IsActive() {
+		updates["left_at"] = time.Now()
+	}
+	return u.ChatMemberRepo.UpdateStatus(ctx, chatID, telegramID, updates)
+}
+
+// Members возвращает участников чата в указанных статусах
+func (u *ChatMemberUseCase) Members(ctx context.Context, chatID int64, statuses ...string) ([]*entity.ChatMember, error) {
+	return u.ChatMemberRepo.GetByChatID(ctx, chatID, statuses)
+}
+
+// ActiveChats возвращает чаты, в которых пользователь сейчас состоит
+func (u *ChatMemberUseCase) ActiveChats(ctx context.Context, telegramID int64) ([]*entity.ChatMember, error) {
+	return u.ChatMemberRepo.GetActiveByTelegramID(ctx, telegramID)
+}
+
+// Stats считает участников чата по статусам и движение за период с since
+func (u *ChatMemberUseCase) Stats(ctx context.Context, chatID int64, since time.Time) (*entity.ChatStats, error) {
+	byStatus, err := u.ChatMemberRepo.CountByStatus(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	joined, err := u.ChatMemberRepo.CountJoinedSince(ctx, chatID, since)
+	if err != nil {
+		return nil, err
+	}
+	left, err := u.ChatMemberRepo.CountLeftSince(ctx, chatID, since)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ChatStats{
+		ByStatus:    byStatus,
+		JoinedSince: joined,
+		LeftSince:   left,
+	}, nil
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+)
+
+type ChatTextUseCase struct {
+	ChatTextRepo repository.ChatTextRepository
+}
+
+func NewChatTextUseCase(repo repository.ChatTextRepository) *ChatTextUseCase {
+	return &ChatTextUseCase{
+		ChatTextRepo: repo,
+	}
+}
+
+// Get возвращает правила или FAQ чата
+func (u *ChatTextUseCase) Get(ctx context.Context, chatID int64, kind string) (*entity.ChatText, error) {
+	return u.ChatTextRepo.Get(ctx, chatID, kind)
+}
+
+// GetAll возвращает тексты всех чатов, нужен для прогрева кеша
+func (u *ChatTextUseCase) GetAll(ctx context.Context) ([]*entity.ChatText, error) {
+	return u.ChatTextRepo.GetAll(ctx)
+}
+
+// Save записывает новую версию текста поверх старой
+func (u *ChatTextUseCase) Save(ctx context.Context, text *entity.ChatText) error {
+	return u.ChatTextRepo.Upsert(ctx, text)
+}
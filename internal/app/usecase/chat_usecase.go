@@ -26,14 +26,19 @@ func (u *ChatUseCase) UpdateThreadID(ctx context.Context, chatID int64, threadID
 	return u.ChatRepo.UpdateThreadID(ctx, chatID, threadID)
 }
 
-// UpdateRulesLink обновляет ссылку на правила для чата
-func (u *ChatUseCase) UpdateRulesLink(ctx context.Context, chatID int64, rulesLink string) error {
-	return u.ChatRepo.UpdateRulesLink(ctx, chatID, rulesLink)
+// UpdateSlowModeDelay обновляет задержку медленного режима для чата
+func (u *ChatUseCase) UpdateSlowModeDelay(ctx context.Context, chatID int64, delay int) error {
+	return u.ChatRepo.UpdateSlowModeDelay(ctx, chatID, delay)
 }
 
-// UpdateRulesLink обновляет ссылку на помощь для чата
-func (u *ChatUseCase) UpdateFaqLink(ctx context.Context, chatID int64, faqLink string) error {
-	return u.ChatRepo.UpdateFaqLink(ctx, chatID, faqLink)
+// UpdateAutoFaqThreshold включает автоответы из FAQ с нужной уверенностью, 0 - выключает
+func (u *ChatUseCase) UpdateAutoFaqThreshold(ctx context.Context, chatID int64, threshold float64) error {
+	return u.ChatRepo.UpdateAutoFaqThreshold(ctx, chatID, threshold)
+}
+
+// UpdateLanguage меняет язык Морти в чате
+func (u *ChatUseCase) UpdateLanguage(ctx context.Context, chatID int64, language string) error {
+	return u.ChatRepo.UpdateLanguage(ctx, chatID, language)
 }
 
 // GetByChatID возвращает информацию о чате
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/pkg/fuzzy"
+	"regexp"
+	"strings"
+)
+
+var ErrFaqBadPattern = errors.New("faq pattern is not a valid regexp")
+
+const (
+	// DefaultAutoFaqThreshold - с какой уверенностью отвечать, если порог не указали
+	DefaultAutoFaqThreshold = 0.8
+	// MinAutoFaqThreshold - ниже Морти начнёт отвечать на всё подряд
+	MinAutoFaqThreshold = 0.5
+	// faqKeywordScore - одно ключевое слово в сообщении ещё не значит, что спрашивают именно это
+	faqKeywordScore = 0.85
+	// faqRejectedOverlap - настолько похожее на отклонённое сообщение считается тем же самым
+	faqRejectedOverlap = 0.7
+)
+
+// entryPatterns - скомпилированные регулярки вопроса и строка, из которой они собраны
+type entryPatterns struct {
+	source   string
+	compiled []*regexp.Regexp
+}
+
+// SetPatterns заменяет регулярки вопроса. Пустой список - автоответ только по словам.
+func (u *FaqUseCase) SetPatterns(ctx context.Context, chatID, actorID, id int64, patterns []string) error {
+	existing, err := u.Get(ctx, chatID, id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkGlobal(ctx, actorID, existing); err != nil {
+		return err
+	}
+	for _, pattern := range patterns {
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("%w: %s", ErrFaqBadPattern, pattern)
+		}
+	}
+	if err := u.FaqEntryRepo.UpdatePatterns(ctx, id, strings.Join(patterns, "\n")); err != nil {
+		return err
+	}
+	u.forgetPatterns(id)
+	return nil
+}
+
+// Rejections возвращает сообщения, на которые в чате ответили невпопад
+func (u *FaqUseCase) Rejections(ctx context.Context, chatID int64) ([]*entity.FaqRejection, error) {
+	return u.FaqRejectionRepo.GetByChatID(ctx, chatID)
+}
+
+// Reject запоминает, что на такое сообщение этот вопрос больше не подходит
+func (u *FaqUseCase) Reject(ctx context.Context, chatID, entryID int64, text string, rejectedBy int64) error {
+	return u.FaqRejectionRepo.Create(ctx, &entity.FaqRejection{
+		ChatID:     chatID,
+		EntryID:    entryID,
+		Text:       text,
+		RejectedBy: rejectedBy,
+	})
+}
+
+// AutoMatch подбирает вопрос к обычному сообщению в чате. Вопросы, которые модераторы
+// уже отклоняли на похожие сообщения, пропускаются. nil - ничего не подошло с нужной уверенностью.
+func (u *FaqUseCase) AutoMatch(entries []*entity.FaqEntry, rejections []*entity.FaqRejection, text string, threshold float64) *entity.FaqMatch {
+	words := fuzzy.Words(text)
+	var best *entity.FaqMatch
+	for _, entry := range entries {
+		score := u.scoreFaqMessage(words, text, entry)
+		if score < threshold || (best != nil && score <= best.Score) {
+			continue
+		}
+		if isRejected(rejections, entry.ID, words) {
+			continue
+		}
+		best = &entity.FaqMatch{Entry: entry, Score: score}
+	}
+	return best
+}
+
+// scoreFaqMessage - насколько сообщение похоже на вопрос. В отличие от поиска через /faq
+// здесь сообщение длинное, а вопрос короткий, поэтому считаем, какая часть вопроса
+// нашлась в сообщении. Совпавшая регулярка - полная уверенность.
+func (u *FaqUseCase) scoreFaqMessage(words []string, text string, entry *entity.FaqEntry) float64 {
+	for _, re := range u.entryRegexps(entry) {
+		if re.MatchString(text) {
+			return 1
+		}
+	}
+	if len(words) == 0 {
+		return 0
+	}
+
+	score := fuzzy.Overlap(fuzzy.Words(entry.Question), words, faqWordThreshold)
+	for _, label := range append(entry.TagList(), entry.KeywordList()...) {
+		labelWords := fuzzy.Words(label)
+		if len(labelWords) > 0 && fuzzy.Overlap(labelWords, words, faqWordThreshold) == 1 {
+			score = max(score, faqKeywordScore)
+		}
+	}
+	return score
+}
+
+func isRejected(rejections []*entity.FaqRejection, entryID int64, words []string) bool {
+	for _, rejection := range rejections {
+		if rejection.EntryID != entryID {
+			continue
+		}
+		rejected := fuzzy.Words(rejection.Text)
+		if min(fuzzy.Overlap(words, rejected, faqWordThreshold), fuzzy.Overlap(rejected, words, faqWordThreshold)) >= faqRejectedOverlap {
+			return true
+		}
+	}
+	return false
+}
+
+// entryRegexps возвращает регулярки вопроса, компилируя их при первом обращении
+// и после изменения. Некомпилирующиеся регулярки пропускаются.
+func (u *FaqUseCase) entryRegexps(entry *entity.FaqEntry) []*regexp.Regexp {
+	if cached, ok := u.patterns.Load(entry.ID); ok && cached.(*entryPatterns).source == entry.Patterns {
+		return cached.(*entryPatterns).compiled
+	}
+	patterns := &entryPatterns{source: entry.Patterns}
+	for _, pattern := range entry.PatternList() {
+		if re, err := compilePattern(pattern); err == nil {
+			patterns.compiled = append(patterns.compiled, re)
+		}
+	}
+	u.patterns.Store(entry.ID, patterns)
+	return patterns.compiled
+}
+
+// forgetPatterns убирает регулярки изменённого или удалённого вопроса из кэша
+func (u *FaqUseCase) forgetPatterns(id int64) {
+	u.patterns.Delete(id)
+}
+
+// compilePattern компилирует регулярку без учёта регистра
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"morty-smith-34-c/internal/app/entity"
+	"testing"
+)
+
+func TestEntryRegexpsFollowPatterns(t *testing.T) {
+	u := NewFaqUseCase(nil, nil, nil)
+	entry := &entity.FaqEntry{ID: 1001, Question: "Где кофе?", Patterns: "кофе\n(("}
+
+	if got := u.scoreFaqMessage(nil, "А где тут КОФЕ?", entry); got != 1 {
+		t.Errorf("score = %v, want 1 for a matching pattern", got)
+	}
+	if got := len(u.entryRegexps(entry)); got != 1 {
+		t.Errorf("compiled %d patterns, want 1 without the broken one", got)
+	}
+
+	// Регулярки поменялись - старые не должны срабатывать
+	entry.Patterns = "чай"
+	if got := u.scoreFaqMessage(nil, "где кофе", entry); got == 1 {
+		t.Error("stale pattern still matches after change")
+	}
+	if got := u.scoreFaqMessage(nil, "где чай", entry); got != 1 {
+		t.Errorf("score = %v, want 1 for the new pattern", got)
+	}
+
+	u.forgetPatterns(entry.ID)
+	if _, ok := u.patterns.Load(entry.ID); ok {
+		t.Error("forgetPatterns left the entry in cache")
+	}
+	// У другого экземпляра свой кэш
+	other := NewFaqUseCase(nil, nil, nil)
+	u.entryRegexps(entry)
+	if _, ok := other.patterns.Load(entry.ID); ok {
+		t.Error("patterns cache is shared between use cases")
+	}
+}
+
+var (
+	faqCampus = &entity.FaqEntry{ID: 1, Question: "Как попасть в кампус?", Keywords: "пропуск"}
+	faqCoffee = &entity.FaqEntry{ID: 2, Question: "Где купить кофе?", Patterns: "кофе(машина|автомат)"}
+	faqWifi   = &entity.FaqEntry{ID: 3, Question: "Какой пароль от wifi?", Tags: "wifi"}
+)
+
+func TestAutoMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		threshold  float64
+		rejections []*entity.FaqRejection
+		want       *entity.FaqEntry // nil - ничего не подошло
+		wantScore  float64
+	}{
+		{"question words", "Как попасть в кампус", DefaultAutoFaqThreshold, nil, faqCampus, 1},
+		{"keyword", "где взять пропуск", DefaultAutoFaqThreshold, nil, faqCampus, faqKeywordScore},
+		{"tag", "не ловит wifi на третьем", DefaultAutoFaqThreshold, nil, faqWifi, faqKeywordScore},
+		{"keyword below threshold", "где взять пропуск", 0.9, nil, nil, 0},
+		{"regexp", "опять сломалась КОФЕМАШИНА", DefaultAutoFaqThreshold, nil, faqCoffee, 1},
+		{"regexp beats keyword", "пропуск забыл у кофеавтомата", DefaultAutoFaqThreshold, nil, faqCoffee, 1},
+		{"unrelated", "всем привет, как дела", MinAutoFaqThreshold, nil, nil, 0},
+		{"empty", "", MinAutoFaqThreshold, nil, nil, 0},
+		{
+			"rejected", "где взять пропуск?", DefaultAutoFaqThreshold,
+			[]*entity.FaqRejection{{EntryID: faqCampus.ID, Text: "Где взять пропуск"}},
+			nil, 0,
+		},
+		{
+			"rejected falls back to next best", "пропуск забыл у кофеавтомата", DefaultAutoFaqThreshold,
+			[]*entity.FaqRejection{{EntryID: faqCoffee.ID, Text: "забыл пропуск у кофеавтомата"}},
+			faqCampus, faqKeywordScore,
+		},
+		{
+			"rejection of another entry", "где взять пропуск", DefaultAutoFaqThreshold,
+			[]*entity.FaqRejection{{EntryID: faqWifi.ID, Text: "где взять пропуск"}},
+			faqCampus, faqKeywordScore,
+		},
+		{
+			"different rejected message", "где взять пропуск", DefaultAutoFaqThreshold,
+			[]*entity.FaqRejection{{EntryID: faqCampus.ID, Text: "пропуск потерялся вместе с рюкзаком и ноутбуком"}},
+			faqCampus, faqKeywordScore,
+		},
+	}
+
+	u := NewFaqUseCase(nil, nil, nil)
+	entries := []*entity.FaqEntry{faqCampus, faqCoffee, faqWifi}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := u.AutoMatch(entries, tt.rejections, tt.text, tt.threshold)
+			if tt.want == nil {
+				if match != nil {
+					t.Errorf("AutoMatch(%q) = %q (%.2f), want nothing", tt.text, match.Entry.Question, match.Score)
+				}
+				return
+			}
+			if match == nil {
+				t.Fatalf("AutoMatch(%q) = nil, want %q", tt.text, tt.want.Question)
+			}
+			if match.Entry != tt.want || match.Score != tt.wantScore {
+				t.Errorf("AutoMatch(%q) = %q (%.2f), want %q (%.2f)", tt.text, match.Entry.Question, match.Score, tt.want.Question, tt.wantScore)
+			}
+		})
+	}
+}
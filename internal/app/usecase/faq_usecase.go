@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"morty-smith-34-c/pkg/fuzzy"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFaqNotFound   = errors.New("faq entry not found")
+	ErrFaqEmpty      = errors.New("faq question or answer is empty")
+	ErrFaqTagTooLong = errors.New("faq tag is too long")
+)
+
+const (
+	// MaxFaqTagBytes - тег уходит в callback_data кнопки, а там не больше 64 байт
+	MaxFaqTagBytes = 48
+	// FaqMatchThreshold - ниже этого вопрос считается не найденным
+	FaqMatchThreshold = 0.5
+	// faqWordThreshold - слово запроса считается найденным, если похоже хотя бы настолько
+	faqWordThreshold = 0.7
+	// faqLabelWeight - теги и ключевые слова важнее слов из текста вопроса
+	faqLabelWeight = 1.2
+)
+
+type FaqUseCase struct {
+	FaqEntryRepo     repository.FaqEntryRepository
+	FaqRejectionRepo repository.FaqRejectionRepository
+	UserUseCase      *UserUseCase
+	// patterns - скомпилированные регулярки по ID вопроса (int64 -> *entryPatterns). Запись пересобирается,
+	// когда регулярки вопроса меняются, и удаляется вместе с вопросом, поэтому кэш не больше базы вопросов.
+	patterns *sync.Map
+}
+
+func NewFaqUseCase(repo repository.FaqEntryRepository, rejectionRepo repository.FaqRejectionRepository, userUseCase *UserUseCase) *FaqUseCase {
+	return &FaqUseCase{
+		FaqEntryRepo:     repo,
+		FaqRejectionRepo: rejectionRepo,
+		UserUseCase:      userUseCase,
+		patterns:         &sync.Map{},
+	}
+}
+
+// List возвращает вопросы чата вместе с общими
+func (u *FaqUseCase) List(ctx context.Context, chatID int64) ([]*entity.FaqEntry, error) {
+	return u.FaqEntryRepo.GetForChat(ctx, chatID)
+}
+
+// Get возвращает вопрос, если он виден в этом чате
+func (u *FaqUseCase) Get(ctx context.Context, chatID, id int64) (*entity.FaqEntry, error) {
+	entry, err := u.FaqEntryRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFaqNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsGlobal() && *entry.ChatID != chatID {
+		return nil, ErrFaqNotFound
+	}
+	return entry, nil
+}
+
+// Add добавляет вопрос. Общие вопросы добавляет только суперадмин.
+func (u *FaqUseCase) Add(ctx context.Context, actorID int64, entry *entity.FaqEntry) error {
+	if err := u.checkGlobal(ctx, actorID, entry); err != nil {
+		return err
+	}
+	if err := normalizeFaqEntry(entry); err != nil {
+		return err
+	}
+	entry.CreatedBy = &actorID
+	return u.FaqEntryRepo.Create(ctx, entry)
+}
+
+// Update заменяет вопрос, ответ, теги и ключевые слова
+func (u *FaqUseCase) Update(ctx context.Context, chatID, actorID int64, entry *entity.FaqEntry) error {
+	existing, err := u.Get(ctx, chatID, entry.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.checkGlobal(ctx, actorID, existing); err != nil {
+		return err
+	}
+	if err := normalizeFaqEntry(entry); err != nil {
+		return err
+	}
+	entry.ChatID = existing.ChatID
+	entry.Patterns = existing.Patterns
+	entry.CreatedBy = existing.CreatedBy
+	entry.CreatedAt = existing.CreatedAt
+	return u.FaqEntryRepo.Update(ctx, entry)
+}
+
+// Delete удаляет вопрос этого чата, общий - только суперадмин
+func (u *FaqUseCase) Delete(ctx context.Context, chatID, actorID, id int64) error {
+	existing, err := u.Get(ctx, chatID, id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkGlobal(ctx, actorID, existing); err != nil {
+		return err
+	}
+	if err := u.FaqEntryRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.forgetPatterns(id)
+	return nil
+}
+
+// Search ищет вопросы по словам запроса, лучшие совпадения первыми
+func (u *FaqUseCase) Search(ctx context.Context, chatID int64, query string) ([]entity.FaqMatch, error) {
+	words := fuzzy.Words(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	entries, err := u.List(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []entity.FaqMatch
+	for _, entry := range entries {
+		if score := scoreFaqEntry(words, entry); score >= FaqMatchThreshold {
+			matches = append(matches, entity.FaqMatch{Entry: entry, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+// FaqCategories - все теги вопросов по алфавиту
+func FaqCategories(entries []*entity.FaqEntry) []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, entry := range entries {
+		for _, tag := range entry.TagList() {
+			if !seen[strings.ToLower(tag)] {
+				seen[strings.ToLower(tag)] = true
+				categories = append(categories, tag)
+			}
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i]) < strings.ToLower(categories[j])
+	})
+	return categories
+}
+
+// FaqInCategory - вопросы с тегом, пустой тег - вопросы без тегов
+func FaqInCategory(entries []*entity.FaqEntry, tag string) []*entity.FaqEntry {
+	var result []*entity.FaqEntry
+	for _, entry := range entries {
+		tags := entry.TagList()
+		if tag == "" && len(tags) == 0 {
+			result = append(result, entry)
+			continue
+		}
+		for _, t := range tags {
+			if strings.EqualFold(t, tag) {
+				result = append(result, entry)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func (u *FaqUseCase) checkGlobal(ctx context.Context, actorID int64, entry *entity.FaqEntry) error {
+	if !entry.IsGlobal() {
+		return nil
+	}
+	role, err := u.UserUseCase.GetGlobalRole(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if role != entity.RoleSuperadmin {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// scoreFaqEntry - средняя похожесть слов запроса на лучшие слова вопроса
+func scoreFaqEntry(words []string, entry *entity.FaqEntry) float64 {
+	question := fuzzy.Words(entry.Question)
+	var labels []string
+	for _, label := range append(entry.TagList(), entry.KeywordList()...) {
+		labels = append(labels, fuzzy.Words(label)...)
+	}
+
+	total := 0.0
+	for _, word := range words {
+		score := max(fuzzy.BestMatch(word, question), min(1, fuzzy.BestMatch(word, labels)*faqLabelWeight))
+		if score >= faqWordThreshold {
+			total += score
+		}
+	}
+	return total / float64(len(words))
+}
+
+// normalizeFaqEntry чистит теги и ключевые слова: без дублей и пустых, ключевые слова в нижнем регистре
+func normalizeFaqEntry(entry *entity.FaqEntry) error {
+	entry.Question = strings.TrimSpace(entry.Question)
+	// Ответом может быть картинка или видео без подписи
+	if entry.Question == "" || (strings.TrimSpace(entry.Answer) == "" && entry.MediaFileID == nil) {
+		return ErrFaqEmpty
+	}
+
+	tags := uniqueList(entry.TagList(), false)
+	for _, tag := range tags {
+		if len(tag) > MaxFaqTagBytes {
+			return ErrFaqTagTooLong
+		}
+	}
+	keywords := uniqueList(entry.KeywordList(), true)
+	entry.Tags = strings.Join(tags, ", ")
+	entry.Keywords = strings.Join(keywords, ", ")
+	return nil
+}
+
+func uniqueList(items []string, lower bool) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, item := range items {
+		key := strings.ToLower(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if lower {
+			item = key
+		}
+		result = append(result, item)
+	}
+	return result
+}
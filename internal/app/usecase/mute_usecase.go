@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"morty-smith-34-c/pkg/logger"
+	"time"
+)
+
+type MuteUseCase struct {
+	MuteRepo repository.MuteRepository
+}
+
+func NewMuteUseCase(repo repository.MuteRepository) *MuteUseCase {
+	return &MuteUseCase{
+		MuteRepo: repo,
+	}
+}
+
+// Mute записывает мут в реестр, until = nil означает бессрочный мут
+func (u *MuteUseCase) Mute(ctx context.Context, chatID, telegramID, moderatorID int64, reason string, until *time.Time) error {
+	mute := &entity.Mute{
+		ChatID:      chatID,
+		TelegramID:  telegramID,
+		ModeratorID: moderatorID,
+		Until:       until,
+		CreatedAt:   time.Now(),
+	}
+	if reason != "" {
+		mute.Reason = &reason
+	}
+	return u.MuteRepo.Upsert(ctx, mute)
+}
+
+// Unmute убирает мут из реестра, возвращает false если его там не было
+func (u *MuteUseCase) Unmute(ctx context.Context, chatID, telegramID int64) (bool, error) {
+	return u.MuteRepo.Delete(ctx, chatID, telegramID)
+}
+
+// GetActive возвращает действующие муты чата
+func (u *MuteUseCase) GetActive(ctx context.Context, chatID int64) ([]*entity.Mute, error) {
+	return u.MuteRepo.GetActiveByChatID(ctx, chatID, time.Now())
+}
+
+// StartCleanup периодически удаляет истёкшие муты, Telegram снимает их сам
+func (u *MuteUseCase) StartCleanup(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				deleted, err := u.MuteRepo.DeleteExpired(ctx, time.Now())
+				if err != nil {
+					log.Error(ctx, "MuteUseCase: failed to cleanup expired mutes", "err", err)
+					continue
+				}
+				if deleted > 0 {
+					log.Debug(ctx, "MuteUseCase: expired mutes removed", "count", deleted)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"time"
+)
+
+// MoscowLocation - расписания ночного режима задаются по московскому времени.
+var MoscowLocation = time.FixedZone("MSK", 3*60*60)
+
+type NightModeUseCase struct {
+	NightModeRepo repository.NightModeRepository
+}
+
+func NewNightModeUseCase(repo repository.NightModeRepository) *NightModeUseCase {
+	return &NightModeUseCase{
+		NightModeRepo: repo,
+	}
+}
+
+// SetSchedule включает ночной режим для чата с указанным окном и ограничением
+func (u *NightModeUseCase) SetSchedule(ctx context.Context, chatID int64, startMinute, endMinute int, restriction string) error {
+	return u.NightModeRepo.Upsert(ctx, &entity.NightMode{
+		ChatID:      chatID,
+		StartMinute: startMinute,
+		EndMinute:   endMinute,
+		Restriction: restriction,
+		Enabled:     true,
+	})
+}
+
+// Disable выключает ночной режим, права вернёт планировщик
+func (u *NightModeUseCase) Disable(ctx context.Context, chatID int64) error {
+	return u.NightModeRepo.Disable(ctx, chatID)
+}
+
+func (u *NightModeUseCase) GetByChatID(ctx context.Context, chatID int64) (*entity.NightMode, error) {
+	return u.NightModeRepo.GetByChatID(ctx, chatID)
+}
+
+func (u *NightModeUseCase) GetScheduled(ctx context.Context) ([]*entity.NightMode, error) {
+	return u.NightModeRepo.GetScheduled(ctx)
+}
+
+// MarkApplied запоминает, что ограничения применены, и права чата до них
+func (u *NightModeUseCase) MarkApplied(ctx context.Context, chatID int64, savedPermissions string) error {
+	return u.NightModeRepo.UpdateActive(ctx, chatID, true, &savedPermissions)
+}
+
+// MarkRestored запоминает, что права чата восстановлены
+func (u *NightModeUseCase) MarkRestored(ctx context.Context, chatID int64) error {
+	return u.NightModeRepo.UpdateActive(ctx, chatID, false, nil)
+}
+
+// ShouldBeActive проверяет, должны ли сейчас действовать ограничения ночного режима.
+// Окно может переходить через полночь, например 01:00-07:00 или 23:00-07:00.
+func (u *NightModeUseCase) ShouldBeActive(nightMode *entity.NightMode, now time.Time) bool {
+	if !nightMode.Enabled || nightMode.StartMinute == nightMode.EndMinute {
+		return false
+	}
+	now = now.In(MoscowLocation)
+	minute := now.Hour()*60 + now.Minute()
+	if nightMode.StartMinute < nightMode.EndMinute {
+		return minute >= nightMode.StartMinute && minute < nightMode.EndMinute
+	}
+	return minute >= nightMode.StartMinute || minute < nightMode.EndMinute
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+)
+
+var (
+	ErrPermissionDenied  = errors.New("permission denied")
+	ErrUnknownCapability = errors.New("unknown capability")
+	ErrImmutableRole     = errors.New("role permissions can't be changed")
+)
+
+// Capabilities - все права, которые можно выдать роли
+var Capabilities = []entity.Capability{
+	entity.CapActivateChat,
+	entity.CapSetTopic,
+	entity.CapEditRules,
+	entity.CapEditFaq,
+	entity.CapTuneFaq,
+	entity.CapManageChat,
+	entity.CapMute,
+	entity.CapBan,
+	entity.CapViewMutes,
+	entity.CapViewStats,
+	entity.CapBypassSlowMode,
+	entity.CapSaveUser,
+	entity.CapWhois,
+	entity.CapManageRoles,
+	entity.CapManagePermissions,
+}
+
+// DefaultCapabilities - права ролей, если в чате их не переопределили.
+// Суперадмину можно всё всегда, поэтому его здесь нет. Настройки чата и роли
+// по умолчанию остаются за суперадмином, админу их выдают через /morty_perm.
+var DefaultCapabilities = map[string][]entity.Capability{
+	entity.RoleUser: {},
+	entity.RoleModer: {
+		entity.CapMute,
+		entity.CapViewMutes,
+		entity.CapViewStats,
+		entity.CapBypassSlowMode,
+		entity.CapWhois,
+		entity.CapTuneFaq,
+	},
+	entity.RoleAdmin: {
+		entity.CapMute,
+		entity.CapBan,
+		entity.CapViewMutes,
+		entity.CapViewStats,
+		entity.CapBypassSlowMode,
+		entity.CapWhois,
+		entity.CapSaveUser,
+		entity.CapTuneFaq,
+	},
+}
+
+type PermissionUseCase struct {
+	UserUseCase        *UserUseCase
+	ChatPermissionRepo repository.ChatPermissionRepository
+}
+
+func NewPermissionUseCase(userUseCase *UserUseCase, repo repository.ChatPermissionRepository) *PermissionUseCase {
+	return &PermissionUseCase{
+		UserUseCase:        userUseCase,
+		ChatPermissionRepo: repo,
+	}
+}
+
+// Authorize - единая проверка прав: может ли пользователь сделать это в чате
+func (u *PermissionUseCase) Authorize(ctx context.Context, chatID, telegramID int64, capability entity.Capability) error {
+	role, err := u.UserUseCase.GetRole(ctx, chatID, telegramID)
+	if err != nil {
+		return err
+	}
+	allowed, err := u.RoleHasCapability(ctx, chatID, role, capability)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// RoleHasCapability учитывает права по умолчанию и переопределения чата
+func (u *PermissionUseCase) RoleHasCapability(ctx context.Context, chatID int64, role string, capability entity.Capability) (bool, error) {
+	capabilities, err := u.RoleCapabilities(ctx, chatID, role)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range capabilities {
+		if c == capability {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RoleCapabilities возвращает итоговый список прав роли в чате
+func (u *PermissionUseCase) RoleCapabilities(ctx context.Context, chatID int64, role string) ([]entity.Capability, error) {
+	if role == entity.RoleSuperadmin {
+		return Capabilities, nil
+	}
+
+	granted := make(map[entity.Capability]bool)
+	for _, c := range DefaultCapabilities[role] {
+		granted[c] = true
+	}
+	overrides, err := u.ChatPermissionRepo.GetByChatIDAndRole(ctx, chatID, role)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		granted[o.Capability] = o.Allowed
+	}
+
+	var capabilities []entity.Capability
+	for _, c := range Capabilities {
+		if granted[c] {
+			capabilities = append(capabilities, c)
+		}
+	}
+	return capabilities, nil
+}
+
+// SetCapability выдаёт или забирает право у роли в чате
+func (u *PermissionUseCase) SetCapability(ctx context.Context, chatID int64, role string, capability entity.Capability, allowed bool) error {
+	if err := validateRoleForOverride(role); err != nil {
+		return err
+	}
+	if !IsValidCapability(capability) {
+		return ErrUnknownCapability
+	}
+	return u.ChatPermissionRepo.Upsert(ctx, &entity.ChatPermission{
+		ChatID:     chatID,
+		Role:       role,
+		Capability: capability,
+		Allowed:    allowed,
+	})
+}
+
+// ResetRole возвращает роли права по умолчанию
+func (u *PermissionUseCase) ResetRole(ctx context.Context, chatID int64, role string) error {
+	if err := validateRoleForOverride(role); err != nil {
+		return err
+	}
+	return u.ChatPermissionRepo.DeleteByRole(ctx, chatID, role)
+}
+
+// IsValidCapability проверяет, что такое право существует
+func IsValidCapability(capability entity.Capability) bool {
+	for _, c := range Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRoleForOverride не даёт настраивать суперадмина, чтобы никто не отрезал себе доступ
+func validateRoleForOverride(role string) error {
+	if !IsValidRole(role) {
+		return ErrUnknownRole
+	}
+	if role == entity.RoleSuperadmin {
+		return ErrImmutableRole
+	}
+	return nil
+}
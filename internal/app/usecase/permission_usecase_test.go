@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+const (
+	chatWithOverrides int64 = 1
+	chatWithDefaults  int64 = 2
+
+	userID       int64 = 10
+	moderID      int64 = 20
+	adminID      int64 = 30
+	superadminID int64 = 40
+)
+
+// fakeUserRepo знает только глобальные роли, остальные методы не нужны
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[int64]*entity.User
+}
+
+func (r *fakeUserRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*entity.User, error) {
+	if user, ok := r.users[telegramID]; ok {
+		return user, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeChatRoleRepo struct {
+	repository.ChatRoleRepository
+	roles map[int64]string // одинаковые во всех чатах
+}
+
+func (r *fakeChatRoleRepo) Get(ctx context.Context, chatID, telegramID int64) (*entity.ChatRole, error) {
+	if role, ok := r.roles[telegramID]; ok {
+		return &entity.ChatRole{ChatID: chatID, TelegramID: telegramID, Role: role}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeChatPermissionRepo struct {
+	permissions []*entity.ChatPermission
+}
+
+func (r *fakeChatPermissionRepo) GetByChatID(ctx context.Context, chatID int64) ([]*entity.ChatPermission, error) {
+	var result []*entity.ChatPermission
+	for _, p := range r.permissions {
+		if p.ChatID == chatID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeChatPermissionRepo) GetByChatIDAndRole(ctx context.Context, chatID int64, role string) ([]*entity.ChatPermission, error) {
+	var result []*entity.ChatPermission
+	for _, p := range r.permissions {
+		if p.ChatID == chatID && p.Role == role {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeChatPermissionRepo) Upsert(ctx context.Context, permission *entity.ChatPermission) error {
+	for _, p := range r.permissions {
+		if p.ChatID == permission.ChatID && p.Role == permission.Role && p.Capability == permission.Capability {
+			p.Allowed = permission.Allowed
+			return nil
+		}
+	}
+	r.permissions = append(r.permissions, permission)
+	return nil
+}
+
+func (r *fakeChatPermissionRepo) DeleteByRole(ctx context.Context, chatID int64, role string) error {
+	kept := r.permissions[:0]
+	for _, p := range r.permissions {
+		if p.ChatID != chatID || p.Role != role {
+			kept = append(kept, p)
+		}
+	}
+	r.permissions = kept
+	return nil
+}
+
+// newTestPermissionUseCase - по пользователю на каждую роль.
+// В chatWithOverrides модеру выдан бан, а админу запрещён мут.
+func newTestPermissionUseCase() (*PermissionUseCase, *fakeChatPermissionRepo) {
+	userUseCase := NewUserUseCase(
+		&fakeUserRepo{users: map[int64]*entity.User{
+			superadminID: {TelegramID: superadminID, Role: entity.RoleSuperadmin},
+		}},
+		&fakeChatRoleRepo{roles: map[int64]string{
+			moderID: entity.RoleModer,
+			adminID: entity.RoleAdmin,
+		}},
+		nil,
+	)
+	permissions := &fakeChatPermissionRepo{permissions: []*entity.ChatPermission{
+		{ChatID: chatWithOverrides, Role: entity.RoleModer, Capability: entity.CapBan, Allowed: true},
+		{ChatID: chatWithOverrides, Role: entity.RoleAdmin, Capability: entity.CapMute, Allowed: false},
+	}}
+	return NewPermissionUseCase(userUseCase, permissions), permissions
+}
+
+func TestDefaultCapabilities(t *testing.T) {
+	tests := []struct {
+		role       string
+		capability entity.Capability
+		want       bool
+	}{
+		{entity.RoleUser, entity.CapMute, false},
+		{entity.RoleUser, entity.CapBan, false},
+		{entity.RoleUser, entity.CapWhois, false},
+		{entity.RoleModer, entity.CapMute, true},
+		{entity.RoleModer, entity.CapBan, false},
+		{entity.RoleModer, entity.CapViewMutes, true},
+		{entity.RoleModer, entity.CapTuneFaq, true},
+		{entity.RoleModer, entity.CapSaveUser, false},
+		{entity.RoleModer, entity.CapManageRoles, false},
+		{entity.RoleAdmin, entity.CapMute, true},
+		{entity.RoleAdmin, entity.CapBan, true},
+		{entity.RoleAdmin, entity.CapSaveUser, true},
+		{entity.RoleAdmin, entity.CapManageRoles, false},
+		{entity.RoleAdmin, entity.CapManageChat, false},
+		{entity.RoleAdmin, entity.CapActivateChat, false},
+		{entity.RoleAdmin, entity.CapEditFaq, false},
+		{entity.RoleAdmin, entity.CapManagePermissions, false},
+		{entity.RoleAdmin, entity.CapEditRules, false},
+		{entity.RoleSuperadmin, entity.CapManagePermissions, true},
+		{entity.RoleSuperadmin, entity.CapActivateChat, true},
+		{entity.RoleSuperadmin, entity.CapBan, true},
+	}
+
+	u, _ := newTestPermissionUseCase()
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+string(tt.capability), func(t *testing.T) {
+			got, err := u.RoleHasCapability(context.Background(), chatWithDefaults, tt.role, tt.capability)
+			if err != nil {
+				t.Fatalf("RoleHasCapability: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultCapabilitiesAreKnown(t *testing.T) {
+	for role, capabilities := range DefaultCapabilities {
+		if !IsValidRole(role) {
+			t.Errorf("DefaultCapabilities has unknown role %q", role)
+		}
+		for _, c := range capabilities {
+			if !IsValidCapability(c) {
+				t.Errorf("%s has unknown capability %q", role, c)
+			}
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name       string
+		chatID     int64
+		telegramID int64
+		capability entity.Capability
+		wantErr    error
+	}{
+		{"unknown user has no rights", chatWithDefaults, 99, entity.CapMute, ErrPermissionDenied},
+		{"user can't mute", chatWithDefaults, userID, entity.CapMute, ErrPermissionDenied},
+		{"moder mutes by default", chatWithDefaults, moderID, entity.CapMute, nil},
+		{"moder can't ban by default", chatWithDefaults, moderID, entity.CapBan, ErrPermissionDenied},
+		{"moder bans where allowed", chatWithOverrides, moderID, entity.CapBan, nil},
+		{"moder keeps other defaults", chatWithOverrides, moderID, entity.CapViewMutes, nil},
+		{"admin bans by default", chatWithDefaults, adminID, entity.CapBan, nil},
+		{"admin mutes by default", chatWithDefaults, adminID, entity.CapMute, nil},
+		{"admin can't mute where denied", chatWithOverrides, adminID, entity.CapMute, ErrPermissionDenied},
+		{"admin can't edit permissions", chatWithDefaults, adminID, entity.CapManagePermissions, ErrPermissionDenied},
+		{"admin can't change roles by default", chatWithDefaults, adminID, entity.CapManageRoles, ErrPermissionDenied},
+		{"superadmin edits permissions", chatWithDefaults, superadminID, entity.CapManagePermissions, nil},
+		{"overrides don't touch superadmin", chatWithOverrides, superadminID, entity.CapMute, nil},
+	}
+
+	u, _ := newTestPermissionUseCase()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := u.Authorize(context.Background(), tt.chatID, tt.telegramID, tt.capability)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetCapability(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		capability entity.Capability
+		allowed    bool
+		wantErr    error
+	}{
+		{"grant", entity.RoleUser, entity.CapWhois, true, nil},
+		{"revoke default", entity.RoleModer, entity.CapMute, false, nil},
+		{"grant ban", entity.RoleModer, entity.CapBan, true, nil},
+		{"unknown role", "moderator", entity.CapMute, true, ErrUnknownRole},
+		{"superadmin is immutable", entity.RoleSuperadmin, entity.CapMute, false, ErrImmutableRole},
+		{"unknown capability", entity.RoleModer, "kick", true, ErrUnknownCapability},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, repo := newTestPermissionUseCase()
+			before := len(repo.permissions)
+			ctx := context.Background()
+
+			err := u.SetCapability(ctx, chatWithDefaults, tt.role, tt.capability, tt.allowed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetCapability = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.permissions) != before {
+					t.Errorf("rejected change was saved")
+				}
+				return
+			}
+
+			got, err := u.RoleHasCapability(ctx, chatWithDefaults, tt.role, tt.capability)
+			if err != nil {
+				t.Fatalf("RoleHasCapability: %v", err)
+			}
+			if got != tt.allowed {
+				t.Errorf("after SetCapability got %v, want %v", got, tt.allowed)
+			}
+			// Другой чат живёт со своими правами
+			other, _ := u.RoleHasCapability(ctx, chatWithOverrides, entity.RoleUser, entity.CapWhois)
+			if other {
+				t.Errorf("override leaked into another chat")
+			}
+		})
+	}
+}
+
+func TestSetCapabilityOverwritesAndResets(t *testing.T) {
+	u, _ := newTestPermissionUseCase()
+	ctx := context.Background()
+
+	if err := u.SetCapability(ctx, chatWithOverrides, entity.RoleModer, entity.CapBan, false); err != nil {
+		t.Fatalf("SetCapability: %v", err)
+	}
+	if err := u.Authorize(ctx, chatWithOverrides, moderID, entity.CapBan); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("Authorize after revoke = %v, want ErrPermissionDenied", err)
+	}
+
+	if err := u.ResetRole(ctx, chatWithOverrides, entity.RoleAdmin); err != nil {
+		t.Fatalf("ResetRole: %v", err)
+	}
+	if err := u.Authorize(ctx, chatWithOverrides, adminID, entity.CapMute); err != nil {
+		t.Errorf("Authorize after reset = %v, want default mute", err)
+	}
+	if err := u.ResetRole(ctx, chatWithOverrides, entity.RoleSuperadmin); !errors.Is(err, ErrImmutableRole) {
+		t.Errorf("ResetRole(superadmin) = %v, want ErrImmutableRole", err)
+	}
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+)
+
+var (
+	ErrUnknownRole    = errors.New("unknown role")
+	ErrNotLowerRole   = errors.New("target role is not lower than actor role")
+	ErrRoleAboveActor = errors.New("role is above actor role")
+	ErrLastSuperadmin = repository.ErrLastSuperadmin
+)
+
+// Roles - роли по возрастанию полномочий
+var Roles = []string{
+	entity.RoleUser,
+	entity.RoleModer,
+	entity.RoleAdmin,
+	entity.RoleSuperadmin,
+}
+
+// RoleRank возвращает место роли в иерархии, -1 для неизвестной роли
+func RoleRank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsValidRole проверяет, что такая роль существует
+func IsValidRole(role string) bool {
+	return RoleRank(role) >= 0
+}
+
+// CanActOn разрешает действовать только над теми, у кого роль в чате строго ниже
+func (u *UserUseCase) CanActOn(ctx context.Context, chatID, actorID, targetID int64) error {
+	actorRole, err := u.GetRole(ctx, chatID, actorID)
+	if err != nil {
+		return err
+	}
+	targetRole, err := u.GetRole(ctx, chatID, targetID)
+	if err != nil {
+		return err
+	}
+	if RoleRank(targetRole) >= RoleRank(actorRole) {
+		return ErrNotLowerRole
+	}
+	return nil
+}
+
+// ChangeRole меняет роль в чате с учётом иерархии: выдавать можно роли не выше своей
+// и только тем, кто ниже тебя. Понизить себя можно, но не последнему суперадмину.
+// Роль superadmin глобальная, остальные действуют только в чате chatID.
+func (u *UserUseCase) ChangeRole(ctx context.Context, chatID, actorID, targetID int64, role string) error {
+	if !IsValidRole(role) {
+		return ErrUnknownRole
+	}
+	actorRole, err := u.GetRole(ctx, chatID, actorID)
+	if err != nil {
+		return err
+	}
+	targetRole, err := u.GetRole(ctx, chatID, targetID)
+	if err != nil {
+		return err
+	}
+
+	if actorID != targetID && RoleRank(targetRole) >= RoleRank(actorRole) {
+		return ErrNotLowerRole
+	}
+	if RoleRank(role) > RoleRank(actorRole) {
+		return ErrRoleAboveActor
+	}
+
+	// Счёт суперадминов и обе записи - в одной транзакции репозитория
+	return u.UserRepo.ChangeRole(ctx, chatID, targetID, role)
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"testing"
+)
+
+const secondSuperadminID int64 = 50
+
+// fakeRoleRepo меняет глобальные роли и роли чата вместе, как транзакция в Postgres
+type fakeRoleRepo struct {
+	fakeUserRepo
+	chat *fakeChatRoleStore
+}
+
+func (r *fakeRoleRepo) ChangeRole(ctx context.Context, chatID, telegramID int64, role string) error {
+	if role == entity.RoleSuperadmin {
+		r.users[telegramID] = &entity.User{TelegramID: telegramID, Role: entity.RoleSuperadmin}
+		return nil
+	}
+	if user, ok := r.users[telegramID]; ok && user.Role == entity.RoleSuperadmin {
+		superadmins := 0
+		for _, u := range r.users {
+			if u.Role == entity.RoleSuperadmin {
+				superadmins++
+			}
+		}
+		if superadmins <= 1 {
+			return repository.ErrLastSuperadmin
+		}
+		user.Role = entity.RoleUser
+	}
+	if role == entity.RoleUser {
+		delete(r.chat.roles, telegramID)
+		return nil
+	}
+	r.chat.roles[telegramID] = &entity.ChatRole{TelegramID: telegramID, ChatID: chatID, Role: role, Source: entity.RoleSourceManual}
+	return nil
+}
+
+// newTestRoleHierarchy - по пользователю на каждую роль, второй суперадмин по желанию
+func newTestRoleHierarchy(secondSuperadmin bool) *UserUseCase {
+	users := map[int64]*entity.User{
+		superadminID: {TelegramID: superadminID, Role: entity.RoleSuperadmin},
+	}
+	if secondSuperadmin {
+		users[secondSuperadminID] = &entity.User{TelegramID: secondSuperadminID, Role: entity.RoleSuperadmin}
+	}
+	chat := &fakeChatRoleStore{roles: map[int64]*entity.ChatRole{
+		moderID: {TelegramID: moderID, Role: entity.RoleModer},
+		adminID: {TelegramID: adminID, Role: entity.RoleAdmin},
+	}}
+	return NewUserUseCase(&fakeRoleRepo{fakeUserRepo: fakeUserRepo{users: users}, chat: chat}, chat, nil)
+}
+
+func TestCanActOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   int64
+		target  int64
+		wantErr error
+	}{
+		{"moder on user", moderID, userID, nil},
+		{"admin on moder", adminID, moderID, nil},
+		{"superadmin on admin", superadminID, adminID, nil},
+		{"user on user", userID, 99, ErrNotLowerRole},
+		{"moder on moder", moderID, moderID, ErrNotLowerRole},
+		{"moder on admin", moderID, adminID, ErrNotLowerRole},
+		{"admin on superadmin", adminID, superadminID, ErrNotLowerRole},
+	}
+
+	u := newTestRoleHierarchy(false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := u.CanActOn(context.Background(), chatWithDefaults, tt.actor, tt.target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CanActOn = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChangeRole(t *testing.T) {
+	tests := []struct {
+		name             string
+		secondSuperadmin bool
+		actor            int64
+		target           int64
+		role             string
+		wantErr          error
+		wantRole         string // роль цели после вызова
+	}{
+		{"admin appoints moder", false, adminID, userID, entity.RoleModer, nil, entity.RoleModer},
+		{"admin appoints admin", false, adminID, userID, entity.RoleAdmin, nil, entity.RoleAdmin},
+		{"admin demotes moder", false, adminID, moderID, entity.RoleUser, nil, entity.RoleUser},
+		{"admin demotes self", false, adminID, adminID, entity.RoleModer, nil, entity.RoleModer},
+		{"superadmin appoints superadmin", false, superadminID, adminID, entity.RoleSuperadmin, nil, entity.RoleSuperadmin},
+		{"unknown role", false, adminID, userID, "moderator", ErrUnknownRole, entity.RoleUser},
+		{"admin can't appoint superadmin", false, adminID, userID, entity.RoleSuperadmin, ErrRoleAboveActor, entity.RoleUser},
+		{"moder can't appoint admin", false, moderID, userID, entity.RoleAdmin, ErrRoleAboveActor, entity.RoleUser},
+		{"moder can't touch admin", false, moderID, adminID, entity.RoleUser, ErrNotLowerRole, entity.RoleAdmin},
+		{"superadmins can't demote each other", true, superadminID, secondSuperadminID, entity.RoleUser, ErrNotLowerRole, entity.RoleSuperadmin},
+		{"last superadmin stays", false, superadminID, superadminID, entity.RoleAdmin, ErrLastSuperadmin, entity.RoleSuperadmin},
+		{"superadmin steps down", true, superadminID, superadminID, entity.RoleAdmin, nil, entity.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestRoleHierarchy(tt.secondSuperadmin)
+			ctx := context.Background()
+
+			err := u.ChangeRole(ctx, chatWithDefaults, tt.actor, tt.target, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangeRole = %v, want %v", err, tt.wantErr)
+			}
+			got, err := u.GetRole(ctx, chatWithDefaults, tt.target)
+			if err != nil {
+				t.Fatalf("GetRole: %v", err)
+			}
+			if got != tt.wantRole {
+				t.Errorf("role after ChangeRole = %q, want %q", got, tt.wantRole)
+			}
+		})
+	}
+}
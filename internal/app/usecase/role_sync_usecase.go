@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+
+	"gorm.io/gorm"
+)
+
+// RoleSyncChange - расхождение роли бота с правами в Telegram
+type RoleSyncChange struct {
+	TelegramID int64
+	Name       string
+	From       string
+	To         string
+}
+
+// RoleSyncReport - итог синхронизации одного чата
+type RoleSyncReport struct {
+	ChatID    int64
+	Granted   []RoleSyncChange // получили роль или она поменялась
+	Revoked   []RoleSyncChange // больше не админы в Telegram, роль снята
+	Conflicts []RoleSyncChange // роль выдана вручную и не совпадает с Telegram, не трогаем
+	Unbacked  []RoleSyncChange // роль выдана вручную, а в Telegram человек не админ, не трогаем
+	OptedOut  []RoleSyncChange // отказались от синхронизации, не трогаем
+}
+
+// RoleSyncOptions - как синхронизировать роли
+type RoleSyncOptions struct {
+	Apply        bool // false - только посчитать разницу
+	RevokeManual bool // снимать и роли из /role у тех, кто в Telegram не админ
+}
+
+// HasChanges сообщает, поменялось ли что-то в ролях
+func (r *RoleSyncReport) HasChanges() bool {
+	return len(r.Granted) > 0 || len(r.Revoked) > 0
+}
+
+type RoleSyncUseCase struct {
+	UserUseCase  *UserUseCase
+	ChatRoleRepo repository.ChatRoleRepository
+	OptOutRepo   repository.RoleSyncOptOutRepository
+}
+
+func NewRoleSyncUseCase(userUseCase *UserUseCase, chatRoleRepo repository.ChatRoleRepository, optOutRepo repository.RoleSyncOptOutRepository) *RoleSyncUseCase {
+	return &RoleSyncUseCase{
+		UserUseCase:  userUseCase,
+		ChatRoleRepo: chatRoleRepo,
+		OptOutRepo:   optOutRepo,
+	}
+}
+
+// Sync сверяет роли чата со списком админов Telegram. Роли, выданные через /role,
+// и роли суперадминов не меняются, снимаются только роли, пришедшие из Telegram.
+// Ручные роли тех, кто в Telegram не админ, попадают в отчёт, а с RevokeManual снимаются.
+func (u *RoleSyncUseCase) Sync(ctx context.Context, chatID int64, admins []entity.ChatAdmin, opts RoleSyncOptions) (*RoleSyncReport, error) {
+	optOuts, err := u.OptOutRepo.GetByChatID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	optedOut := make(map[int64]bool, len(optOuts))
+	for _, o := range optOuts {
+		optedOut[o.TelegramID] = true
+	}
+
+	chatRoles, err := u.ChatRoleRepo.GetByChatID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int64]*entity.ChatRole, len(chatRoles))
+	for _, r := range chatRoles {
+		current[r.TelegramID] = r
+	}
+
+	report := &RoleSyncReport{ChatID: chatID}
+	desired := make(map[int64]bool, len(admins))
+	for _, admin := range admins {
+		if admin.Role == entity.RoleUser {
+			continue
+		}
+		desired[admin.TelegramID] = true
+
+		globalRole, err := u.UserUseCase.GetGlobalRole(ctx, admin.TelegramID)
+		if err != nil {
+			return nil, err
+		}
+		if globalRole == entity.RoleSuperadmin {
+			continue
+		}
+
+		from := entity.RoleUser
+		chatRole := current[admin.TelegramID]
+		if chatRole != nil {
+			from = chatRole.Role
+		}
+		if from == admin.Role {
+			continue
+		}
+		change := RoleSyncChange{TelegramID: admin.TelegramID, Name: admin.Name, From: from, To: admin.Role}
+
+		switch {
+		case optedOut[admin.TelegramID]:
+			report.OptedOut = append(report.OptedOut, change)
+		case chatRole != nil && chatRole.Source == entity.RoleSourceManual:
+			report.Conflicts = append(report.Conflicts, change)
+		default:
+			if opts.Apply {
+				err := u.ChatRoleRepo.Upsert(ctx, &entity.ChatRole{
+					TelegramID: admin.TelegramID,
+					ChatID:     chatID,
+					Role:       admin.Role,
+					Source:     entity.RoleSourceTelegram,
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+			report.Granted = append(report.Granted, change)
+		}
+	}
+
+	for _, chatRole := range chatRoles {
+		if chatRole.Role == entity.RoleUser || desired[chatRole.TelegramID] {
+			continue
+		}
+		change := RoleSyncChange{
+			TelegramID: chatRole.TelegramID,
+			From:       chatRole.Role,
+			To:         entity.RoleUser,
+		}
+		if chatRole.Source != entity.RoleSourceTelegram {
+			// Ручная роль без админки в Telegram: отказавшихся не трогаем вовсе,
+			// остальных снимаем, только если об этом попросили
+			if optedOut[chatRole.TelegramID] {
+				continue
+			}
+			globalRole, err := u.UserUseCase.GetGlobalRole(ctx, chatRole.TelegramID)
+			if err != nil {
+				return nil, err
+			}
+			if globalRole == entity.RoleSuperadmin {
+				continue
+			}
+			if !opts.RevokeManual {
+				report.Unbacked = append(report.Unbacked, change)
+				continue
+			}
+		}
+		if opts.Apply {
+			if err := u.ChatRoleRepo.Delete(ctx, chatID, chatRole.TelegramID); err != nil {
+				return nil, err
+			}
+		}
+		report.Revoked = append(report.Revoked, change)
+	}
+	return report, nil
+}
+
+// SetOptOut включает или выключает синхронизацию для пользователя в чате.
+// При отказе роль, пришедшая из Telegram, снимается сразу.
+func (u *RoleSyncUseCase) SetOptOut(ctx context.Context, chatID, telegramID int64, optOut bool) error {
+	if !optOut {
+		return u.OptOutRepo.Delete(ctx, chatID, telegramID)
+	}
+	if err := u.OptOutRepo.Create(ctx, &entity.RoleSyncOptOut{ChatID: chatID, TelegramID: telegramID}); err != nil {
+		return err
+	}
+	chatRole, err := u.ChatRoleRepo.Get(ctx, chatID, telegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if chatRole.Source != entity.RoleSourceTelegram {
+		return nil
+	}
+	return u.ChatRoleRepo.Delete(ctx, chatID, telegramID)
+}
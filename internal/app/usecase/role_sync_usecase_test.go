@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fakeChatRoleStore - роли одного чата
+type fakeChatRoleStore struct {
+	roles map[int64]*entity.ChatRole
+}
+
+func (r *fakeChatRoleStore) Get(ctx context.Context, chatID, telegramID int64) (*entity.ChatRole, error) {
+	if role, ok := r.roles[telegramID]; ok {
+		return role, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeChatRoleStore) GetByChatID(ctx context.Context, chatID int64) ([]*entity.ChatRole, error) {
+	var result []*entity.ChatRole
+	for _, role := range r.roles {
+		result = append(result, role)
+	}
+	return result, nil
+}
+
+func (r *fakeChatRoleStore) Upsert(ctx context.Context, chatRole *entity.ChatRole) error {
+	r.roles[chatRole.TelegramID] = chatRole
+	return nil
+}
+
+func (r *fakeChatRoleStore) Delete(ctx context.Context, chatID, telegramID int64) error {
+	delete(r.roles, telegramID)
+	return nil
+}
+
+type fakeOptOutRepo struct {
+	repository.RoleSyncOptOutRepository
+	optOuts []*entity.RoleSyncOptOut
+}
+
+func (r *fakeOptOutRepo) GetByChatID(ctx context.Context, chatID int64) ([]*entity.RoleSyncOptOut, error) {
+	return r.optOuts, nil
+}
+
+const (
+	syncedAdminID   int64 = 1 // админ в Telegram, роль из синхронизации
+	demotedSyncedID int64 = 2 // был админом, роль из синхронизации
+	manualModerID   int64 = 3 // модер через /role, всё ещё админ в Telegram
+	demotedManualID int64 = 4 // модер через /role, в Telegram уже не админ
+	optedOutID      int64 = 5 // ручная роль, от синхронизации отказался
+	syncSuperadmin  int64 = 6
+)
+
+func newTestRoleSync() (*RoleSyncUseCase, *fakeChatRoleStore) {
+	roles := &fakeChatRoleStore{roles: map[int64]*entity.ChatRole{
+		syncedAdminID:   {TelegramID: syncedAdminID, Role: entity.RoleAdmin, Source: entity.RoleSourceTelegram},
+		demotedSyncedID: {TelegramID: demotedSyncedID, Role: entity.RoleModer, Source: entity.RoleSourceTelegram},
+		manualModerID:   {TelegramID: manualModerID, Role: entity.RoleModer, Source: entity.RoleSourceManual},
+		demotedManualID: {TelegramID: demotedManualID, Role: entity.RoleModer, Source: entity.RoleSourceManual},
+		optedOutID:      {TelegramID: optedOutID, Role: entity.RoleAdmin, Source: entity.RoleSourceManual},
+		syncSuperadmin:  {TelegramID: syncSuperadmin, Role: entity.RoleAdmin, Source: entity.RoleSourceManual},
+	}}
+	userUseCase := NewUserUseCase(
+		&fakeUserRepo{users: map[int64]*entity.User{
+			syncSuperadmin: {TelegramID: syncSuperadmin, Role: entity.RoleSuperadmin},
+		}},
+		roles,
+		nil,
+	)
+	optOuts := &fakeOptOutRepo{optOuts: []*entity.RoleSyncOptOut{{TelegramID: optedOutID}}}
+	return NewRoleSyncUseCase(userUseCase, roles, optOuts), roles
+}
+
+var syncAdmins = []entity.ChatAdmin{
+	{TelegramID: syncedAdminID, Role: entity.RoleAdmin},
+	{TelegramID: manualModerID, Role: entity.RoleModer},
+}
+
+func changedIDs(changes []RoleSyncChange) map[int64]bool {
+	ids := make(map[int64]bool, len(changes))
+	for _, c := range changes {
+		ids[c.TelegramID] = true
+	}
+	return ids
+}
+
+func TestRoleSyncReportsDemotedManualRoles(t *testing.T) {
+	u, roles := newTestRoleSync()
+	report, err := u.Sync(context.Background(), 1, syncAdmins, RoleSyncOptions{Apply: true})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	revoked := changedIDs(report.Revoked)
+	if len(revoked) != 1 || !revoked[demotedSyncedID] {
+		t.Errorf("Revoked = %+v, want only the synced role", report.Revoked)
+	}
+	unbacked := changedIDs(report.Unbacked)
+	if len(unbacked) != 1 || !unbacked[demotedManualID] {
+		t.Errorf("Unbacked = %+v, want only the demoted manual moder", report.Unbacked)
+	}
+	if _, ok := roles.roles[demotedManualID]; !ok {
+		t.Error("manual role was revoked without RevokeManual")
+	}
+	if _, ok := roles.roles[demotedSyncedID]; ok {
+		t.Error("synced role of a demoted admin was kept")
+	}
+}
+
+func TestRoleSyncRevokeManual(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply bool
+	}{
+		{"apply", true},
+		{"dry", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, roles := newTestRoleSync()
+			report, err := u.Sync(context.Background(), 1, syncAdmins, RoleSyncOptions{Apply: tt.apply, RevokeManual: true})
+			if err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if len(report.Unbacked) != 0 {
+				t.Errorf("Unbacked = %+v, want empty with RevokeManual", report.Unbacked)
+			}
+			revoked := changedIDs(report.Revoked)
+			if len(revoked) != 2 || !revoked[demotedSyncedID] || !revoked[demotedManualID] {
+				t.Errorf("Revoked = %+v, want synced and manual demoted roles", report.Revoked)
+			}
+			if _, kept := roles.roles[demotedManualID]; kept == tt.apply {
+				t.Errorf("manual role kept = %v with Apply %v", kept, tt.apply)
+			}
+			for _, id := range []int64{manualModerID, optedOutID, syncSuperadmin} {
+				if _, ok := roles.roles[id]; !ok {
+					t.Errorf("role of %d must not be touched", id)
+				}
+			}
+		})
+	}
+}
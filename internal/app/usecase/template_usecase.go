@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+)
+
+type TemplateUseCase struct {
+	MessageTemplateRepo repository.MessageTemplateRepository
+}
+
+func NewTemplateUseCase(repo repository.MessageTemplateRepository) *TemplateUseCase {
+	return &TemplateUseCase{
+		MessageTemplateRepo: repo,
+	}
+}
+
+// Overrides возвращает шаблоны, переопределённые в чате
+func (u *TemplateUseCase) Overrides(ctx context.Context, chatID int64) ([]*entity.MessageTemplate, error) {
+	return u.MessageTemplateRepo.GetByChatID(ctx, chatID)
+}
+
+// Set переопределяет шаблон в чате. Проверять шаблон должен вызывающий:
+// только он знает, какие переменные в нём доступны.
+func (u *TemplateUseCase) Set(ctx context.Context, chatID int64, name, body string, updatedBy int64) error {
+	return u.MessageTemplateRepo.Upsert(ctx, &entity.MessageTemplate{
+		ChatID:    chatID,
+		Name:      name,
+		Body:      body,
+		UpdatedBy: &updatedBy,
+	})
+}
+
+// Reset возвращает шаблон по умолчанию
+func (u *TemplateUseCase) Reset(ctx context.Context, chatID int64, name string) error {
+	return u.MessageTemplateRepo.Delete(ctx, chatID, name)
+}
@@ -5,16 +5,23 @@ import (
 	"errors"
 	"morty-smith-34-c/internal/app/entity"
 	"morty-smith-34-c/internal/app/repository"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type UserUseCase struct {
-	UserRepo repository.UserRepository
+	UserRepo        repository.UserRepository
+	ChatRoleRepo    repository.ChatRoleRepository
+	NickHistoryRepo repository.NickHistoryRepository
 }
 
-func NewUserUseCase(repo repository.UserRepository) *UserUseCase {
+func NewUserUseCase(repo repository.UserRepository, chatRoleRepo repository.ChatRoleRepository, nickHistoryRepo repository.NickHistoryRepository) *UserUseCase {
 	return &UserUseCase{
-		UserRepo: repo,
+		UserRepo:        repo,
+		ChatRoleRepo:    chatRoleRepo,
+		NickHistoryRepo: nickHistoryRepo,
 	}
 }
 
@@ -23,40 +30,116 @@ func (u *UserUseCase) RegisterUser(ctx context.Context, user *entity.User) error
 	return u.UserRepo.Create(ctx, user)
 }
 
-func (u *UserUseCase) CheckRole(ctx context.Context, telegramID int64, allowedRoles []string) error {
-	user, err := u.UserRepo.GetByTelegramID(ctx, telegramID)
+// GetByTelegramID возвращает пользователя по его Telegram ID
+func (u *UserUseCase) GetByTelegramID(ctx context.Context, telegramID int64) (*entity.User, error) {
+	return u.UserRepo.GetByTelegramID(ctx, telegramID)
+}
+
+// GetBySchoolName ищет пользователя по школьному нику
+func (u *UserUseCase) GetBySchoolName(ctx context.Context, schoolName string) (*entity.User, error) {
+	return u.UserRepo.GetBySchoolName(ctx, strings.ToLower(schoolName))
+}
+
+// FindBySchoolName ищет по нынешнему школьному нику, а если его ни у кого нет -
+// того, кто носил его последним, по истории ников
+func (u *UserUseCase) FindBySchoolName(ctx context.Context, schoolName string) (*entity.User, error) {
+	schoolName = strings.ToLower(schoolName)
+	user, err := u.UserRepo.GetBySchoolName(ctx, schoolName)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+	record, err := u.NickHistoryRepo.GetLatestBySchoolName(ctx, schoolName)
 	if err != nil {
-		return errors.New("user not found")
+		return nil, err
 	}
+	return u.UserRepo.GetByTelegramID(ctx, record.TelegramID)
+}
 
-	for _, role := range allowedRoles {
-		if user.Role == role {
-			return nil
-		}
+// GetByUsername ищет пользователя по последнему известному username без @
+func (u *UserUseCase) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return u.UserRepo.GetByUsername(ctx, strings.ToLower(strings.TrimPrefix(username, "@")))
+}
+
+// RememberUsername сохраняет username проверенного пользователя, незнакомцев пропускает
+func (u *UserUseCase) RememberUsername(ctx context.Context, telegramID int64, username string) error {
+	return u.UserRepo.UpdateUsername(ctx, telegramID, nickOrNil(username))
+}
+
+// GetRole возвращает роль пользователя в чате. Суперадмины бота - суперадмины везде,
+// остальным роль выдаётся в каждом чате отдельно, незнакомцы считаются обычными пользователями
+func (u *UserUseCase) GetRole(ctx context.Context, chatID, telegramID int64) (string, error) {
+	globalRole, err := u.GetGlobalRole(ctx, telegramID)
+	if err != nil {
+		return "", err
+	}
+	if globalRole == entity.RoleSuperadmin {
+		return globalRole, nil
 	}
 
-	return errors.New("permission denied")
+	chatRole, err := u.ChatRoleRepo.Get(ctx, chatID, telegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.RoleUser, nil
+		}
+		return "", err
+	}
+	return chatRole.Role, nil
 }
 
-func (u *UserUseCase) UpdateRole(ctx context.Context, telegramID int64, role string) error {
-	return u.UserRepo.UpdateRole(ctx, telegramID, role)
+// GetGlobalRole возвращает роль из users, она отличается от user только у суперадминов
+func (u *UserUseCase) GetGlobalRole(ctx context.Context, telegramID int64) (string, error) {
+	user, err := u.UserRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.RoleUser, nil
+		}
+		return "", err
+	}
+	return user.Role, nil
 }
 
-func (u *UserUseCase) SaveNickname(ctx context.Context, telegramID int64, nickname string) error {
+// SaveNickname сохраняет нового пользователя. Пустой nickname - одобрен без школьного ника,
+// setBy - кто выдал ник, nil если пользователь прошёл проверку сам.
+func (u *UserUseCase) SaveNickname(ctx context.Context, telegramID int64, nickname, source string, setBy *int64) error {
 	user := &entity.User{
 		TelegramID: telegramID,
-		SchoolName: nickname,
-		Role:       "user",
+		SchoolName: nickOrNil(nickname),
+		Role:       entity.RoleUser,
 		CreatedAt:  time.Now(),
 	}
-
-	return u.UserRepo.Create(ctx, user)
+	return u.UserRepo.CreateWithNick(ctx, user, nickRecord(telegramID, user.SchoolName, source, setBy))
 }
 
 func (u *UserUseCase) Exists(ctx context.Context, telegramID int64) (bool, error) {
 	return u.UserRepo.Exists(ctx, telegramID)
 }
 
-func (u *UserUseCase) UpdateSchoolNick(ctx context.Context, telegramID int64, nick string) (bool, error) {
-	return u.UserRepo.UpdateSchoolNick(ctx, telegramID, nick)
+// UpdateSchoolNick меняет ник и записывает смену в историю. Возвращает false, если ник тот же.
+func (u *UserUseCase) UpdateSchoolNick(ctx context.Context, telegramID int64, nick, source string, setBy *int64) (bool, error) {
+	schoolName := nickOrNil(nick)
+	return u.UserRepo.UpdateSchoolNick(ctx, telegramID, schoolName, nickRecord(telegramID, schoolName, source, setBy))
+}
+
+// NickHistory возвращает историю ников пользователя, свежие записи первыми
+func (u *UserUseCase) NickHistory(ctx context.Context, telegramID int64) ([]*entity.NickHistory, error) {
+	return u.NickHistoryRepo.GetByTelegramID(ctx, telegramID)
+}
+
+// nickRecord - запись истории, которую репозиторий сохранит вместе с ником
+func nickRecord(telegramID int64, schoolName *string, source string, setBy *int64) *entity.NickHistory {
+	return &entity.NickHistory{
+		TelegramID: telegramID,
+		SchoolName: schoolName,
+		Source:     source,
+		SetBy:      setBy,
+	}
+}
+
+// nickOrNil переводит пустой ник в NULL, ники храним в нижнем регистре
+func nickOrNil(nick string) *string {
+	nick = strings.ToLower(strings.TrimSpace(nick))
+	if nick == "" {
+		return nil
+	}
+	return &nick
 }
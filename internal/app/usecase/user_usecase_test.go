@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func (r *fakeUserRepo) GetBySchoolName(ctx context.Context, schoolName string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.SchoolName != nil && *user.SchoolName == schoolName {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeNickHistoryRepo - записи в порядке добавления
+type fakeNickHistoryRepo struct {
+	records []*entity.NickHistory
+}
+
+func (r *fakeNickHistoryRepo) GetByTelegramID(ctx context.Context, telegramID int64) ([]*entity.NickHistory, error) {
+	var result []*entity.NickHistory
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].TelegramID == telegramID {
+			result = append(result, r.records[i])
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeNickHistoryRepo) GetLatestBySchoolName(ctx context.Context, schoolName string) (*entity.NickHistory, error) {
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].SchoolName != nil && *r.records[i].SchoolName == schoolName {
+			return r.records[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func nick(s string) *string {
+	return &s
+}
+
+func TestFindBySchoolName(t *testing.T) {
+	users := &fakeUserRepo{users: map[int64]*entity.User{
+		1: {TelegramID: 1, SchoolName: nick("brieyele")},
+		2: {TelegramID: 2, SchoolName: nick("rickyaso")},
+	}}
+	history := &fakeNickHistoryRepo{records: []*entity.NickHistory{
+		{TelegramID: 1, SchoolName: nick("mortysmi")},
+		{TelegramID: 2, SchoolName: nick("brieyele")}, // ник потом ушёл к первому
+		{TelegramID: 2, SchoolName: nick("rickyaso")},
+		{TelegramID: 1, SchoolName: nick("brieyele")},
+	}}
+	u := NewUserUseCase(users, nil, history)
+
+	tests := []struct {
+		name    string
+		nick    string
+		want    int64
+		wantErr error
+	}{
+		{"current nick", "rickyaso", 2, nil},
+		{"current nick beats history", "brieyele", 1, nil},
+		{"case insensitive", "RickYaso", 2, nil},
+		{"former nick", "mortysmi", 1, nil},
+		{"unknown nick", "jerrysmi", 0, gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := u.FindBySchoolName(context.Background(), tt.nick)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindBySchoolName(%q) error = %v, want %v", tt.nick, err, tt.wantErr)
+			}
+			if err == nil && user.TelegramID != tt.want {
+				t.Errorf("FindBySchoolName(%q) = %d, want %d", tt.nick, user.TelegramID, tt.want)
+			}
+		})
+	}
+}
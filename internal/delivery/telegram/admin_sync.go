@@ -0,0 +1,128 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// AdminSyncer сверяет роли бота с админами чатов в Telegram.
+// Периодическая синхронизация включается только если задан интервал.
+type AdminSyncer struct {
+	ChatUseCase     *usecase.ChatUseCase
+	RoleSyncUseCase *usecase.RoleSyncUseCase
+	interval        time.Duration
+	logger          *logger.Logger
+}
+
+func NewAdminSyncer(logger *logger.Logger, chatUseCase *usecase.ChatUseCase, roleSyncUseCase *usecase.RoleSyncUseCase, interval time.Duration) *AdminSyncer {
+	return &AdminSyncer{
+		ChatUseCase:     chatUseCase,
+		RoleSyncUseCase: roleSyncUseCase,
+		interval:        interval,
+		logger:          logger,
+	}
+}
+
+// Start запускает периодическую синхронизацию всех активированных чатов.
+func (s *AdminSyncer) Start(ctx context.Context, b *bot.Bot) {
+	if s.interval <= 0 {
+		s.logger.Info(ctx, "Admin sync is disabled")
+		return
+	}
+	go func() {
+		s.logger.Info(ctx, "Starting admin sync", "interval", s.interval.String())
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.syncAll(ctx, b)
+		for {
+			select {
+			case <-ticker.C:
+				s.syncAll(ctx, b)
+			case <-ctx.Done():
+				s.logger.Info(ctx, "Stopping admin sync")
+				return
+			}
+		}
+	}()
+}
+
+func (s *AdminSyncer) syncAll(ctx context.Context, b *bot.Bot) {
+	chats, err := s.ChatUseCase.GetAllChats(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "AdminSyncer: failed to load chats", "err", err)
+		return
+	}
+	for _, chat := range chats {
+		report, err := s.SyncChat(ctx, b, chat.ChatID, usecase.RoleSyncOptions{Apply: true})
+		if err != nil {
+			s.logger.Error(ctx, "AdminSyncer: sync failed", "chat", chat.ChatID, "err", err)
+			continue
+		}
+		if report.HasChanges() || len(report.Conflicts) > 0 || len(report.Unbacked) > 0 {
+			s.logger.Info(ctx, "AdminSyncer: roles synced",
+				"chat", chat.ChatID,
+				"granted", len(report.Granted),
+				"revoked", len(report.Revoked),
+				"conflicts", len(report.Conflicts),
+				"unbacked", len(report.Unbacked),
+			)
+		}
+	}
+}
+
+// SyncChat читает админов чата из Telegram и сверяет с ними роли.
+// Без opts.Apply только возвращает разницу.
+func (s *AdminSyncer) SyncChat(ctx context.Context, b *bot.Bot, chatID int64, opts usecase.RoleSyncOptions) (*usecase.RoleSyncReport, error) {
+	members, err := b.GetChatAdministrators(ctx, &bot.GetChatAdministratorsParams{ChatID: chatID})
+	if err != nil {
+		return nil, err
+	}
+	admins := make([]entity.ChatAdmin, 0, len(members))
+	for _, member := range members {
+		user, role := AdminRole(member)
+		if user == nil || user.IsBot {
+			continue
+		}
+		admins = append(admins, entity.ChatAdmin{
+			TelegramID: user.ID,
+			Name:       strings.TrimSpace(user.FirstName + " " + user.LastName),
+			Role:       role,
+		})
+	}
+	return s.RoleSyncUseCase.Sync(ctx, chatID, admins, opts)
+}
+
+// AdminRole переводит права админа Telegram в роль бота:
+// владелец и те, кто может назначать админов или менять чат, - admin,
+// те, кто может ограничивать участников или удалять сообщения, - moder.
+func AdminRole(member models.ChatMember) (*models.User, string) {
+	switch member.Type {
+	case models.ChatMemberTypeOwner:
+		if member.Owner == nil {
+			return nil, entity.RoleUser
+		}
+		return member.Owner.User, entity.RoleAdmin
+	case models.ChatMemberTypeAdministrator:
+		if member.Administrator == nil {
+			return nil, entity.RoleUser
+		}
+		admin := member.Administrator
+		switch {
+		case admin.CanPromoteMembers || admin.CanChangeInfo:
+			return &admin.User, entity.RoleAdmin
+		case admin.CanRestrictMembers || admin.CanDeleteMessages:
+			return &admin.User, entity.RoleModer
+		}
+		return &admin.User, entity.RoleUser
+	}
+	return nil, entity.RoleUser
+}
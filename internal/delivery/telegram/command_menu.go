@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// SyncCommandMenus публикует меню команд в Telegram: участникам групп - общие команды,
+// админам чатов - ещё и те, что роль admin может по умолчанию, в личке - то, что там работает.
+// Права в конкретном чате могут отличаться, полный список по правам показывает /help.
+// Меню на языке по умолчанию видят все, переводы - те, у кого такой язык клиента.
+func (r *Router) SyncCommandMenus(ctx context.Context, b *bot.Bot) error {
+	for _, lang := range r.i18n.Languages() {
+		languageCode := lang
+		if lang == DefaultLanguage {
+			languageCode = ""
+		}
+		if err := r.syncCommandMenus(ctx, b, r.i18n.Printer(lang), languageCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Router) syncCommandMenus(ctx context.Context, b *bot.Bot, p Printer, languageCode string) error {
+	members, admins, private := []models.BotCommand{}, []models.BotCommand{}, []models.BotCommand{}
+	for _, cmd := range r.ordered {
+		if cmd.Hidden {
+			continue
+		}
+		botCommand := models.BotCommand{Command: cmd.Name, Description: cmd.Description(p)}
+		public := cmd.Capability == ""
+		inGroups := cmd.AllowedIn(models.ChatTypeSupergroup) || cmd.AllowedIn(models.ChatTypeGroup)
+
+		if public && inGroups {
+			members = append(members, botCommand)
+		}
+		if inGroups && (public || defaultRoleCan(entity.RoleAdmin, cmd.Capability)) {
+			admins = append(admins, botCommand)
+		}
+		if public && cmd.AllowedIn(models.ChatTypePrivate) {
+			private = append(private, botCommand)
+		}
+	}
+
+	menus := []struct {
+		scope    models.BotCommandScope
+		commands []models.BotCommand
+	}{
+		{&models.BotCommandScopeDefault{}, members},
+		{&models.BotCommandScopeAllGroupChats{}, members},
+		{&models.BotCommandScopeAllChatAdministrators{}, admins},
+		{&models.BotCommandScopeAllPrivateChats{}, private},
+	}
+	for _, menu := range menus {
+		if _, err := b.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands:     menu.commands,
+			Scope:        menu.scope,
+			LanguageCode: languageCode,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultRoleCan проверяет право роли без учёта настроек чата
+func defaultRoleCan(role string, capability entity.Capability) bool {
+	for _, c := range usecase.DefaultCapabilities[role] {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
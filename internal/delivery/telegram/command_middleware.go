@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// RecoverMiddleware не даёт панике в одной команде уронить бота
+func RecoverMiddleware(log *logger.Logger) CommandMiddleware {
+	return func(cmd *Command, next CommandFunc) CommandFunc {
+		return func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error(ctx, "Router: panic in command",
+						"command", cmd.Name,
+						"text", msg.Text,
+						"user", UserForLogger(msg.From),
+						"chat", ChatForLogger(msg.Chat),
+						"panic", r,
+						"stack", string(debug.Stack()),
+					)
+				}
+			}()
+			next(ctx, b, msg, args)
+		}
+	}
+}
+
+// LoggingMiddleware пишет в лог каждую команду и время её выполнения
+func LoggingMiddleware(log *logger.Logger) CommandMiddleware {
+	return func(cmd *Command, next CommandFunc) CommandFunc {
+		return func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+			start := time.Now()
+			next(ctx, b, msg, args)
+			log.Debug(ctx, "Router: command handled",
+				"command", cmd.Name,
+				"text", msg.Text,
+				"user", UserForLogger(msg.From),
+				"chat", ChatForLogger(msg.Chat),
+				"took", time.Since(start).String(),
+			)
+		}
+	}
+}
+
+// AuthMiddleware молча игнорирует команду, если у пользователя нет нужного права
+func AuthMiddleware(log *logger.Logger, permissionUseCase *usecase.PermissionUseCase) CommandMiddleware {
+	return func(cmd *Command, next CommandFunc) CommandFunc {
+		if cmd.Capability == "" {
+			return next
+		}
+		return func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+			if err := permissionUseCase.Authorize(ctx, msg.Chat.ID, msg.From.ID, cmd.Capability); err != nil {
+				log.Debug(ctx, "Router: permission denied",
+					"command", cmd.Name,
+					"capability", string(cmd.Capability),
+					"text", msg.Text,
+					"user", UserForLogger(msg.From),
+					"chat", ChatForLogger(msg.Chat),
+					"err", err,
+				)
+				return
+			}
+			next(ctx, b, msg, args)
+		}
+	}
+}
+
+type rateLimitKey struct {
+	chatID int64
+	userID int64
+}
+
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimitMiddleware пропускает не больше limit команд от пользователя в чате за window.
+// Лишние команды игнорируются, чтобы Морти не заспамили.
+func RateLimitMiddleware(log *logger.Logger, limit int, window time.Duration) CommandMiddleware {
+	var mu sync.Mutex
+	windows := make(map[rateLimitKey]*rateLimitWindow)
+
+	allow := func(key rateLimitKey, now time.Time) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		w, ok := windows[key]
+		if !ok || now.Sub(w.start) >= window {
+			if len(windows) >= 1000 {
+				for k, old := range windows {
+					if now.Sub(old.start) >= window {
+						delete(windows, k)
+					}
+				}
+			}
+			windows[key] = &rateLimitWindow{start: now, count: 1}
+			return true
+		}
+		if w.count >= limit {
+			return false
+		}
+		w.count++
+		return true
+	}
+
+	return func(cmd *Command, next CommandFunc) CommandFunc {
+		return func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+			if !allow(rateLimitKey{chatID: msg.Chat.ID, userID: msg.From.ID}, time.Now()) {
+				log.Debug(ctx, "Router: rate limited",
+					"command", cmd.Name,
+					"text", msg.Text,
+					"user", UserForLogger(msg.From),
+					"chat", ChatForLogger(msg.Chat),
+				)
+				return
+			}
+			next(ctx, b, msg, args)
+		}
+	}
+}
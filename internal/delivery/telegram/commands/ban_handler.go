@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleBan handles the /ban command
+func (h *CommandHandler) handleBan(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, rest, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		h.logger.Debug(ctx, "handleBan: target not found", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyTargetNotFound(ctx, b, msg, err)
+		return
+	}
+
+	if target.ID == msg.From.ID {
+		h.logger.Debug(ctx, "handleBan: banned yourself try", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("ban.self"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+		return
+	}
+
+	if !h.canActOn(ctx, b, msg, target) {
+		return
+	}
+
+	// Бан без срока: until_date = 0
+	_, err = b.BanChatMember(ctx, &bot.BanChatMemberParams{
+		ChatID: msg.Chat.ID,
+		UserID: target.ID,
+	})
+	if err != nil {
+		h.logger.Debug(ctx, "handleBan: cant ban",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.Markdown("ban.failed", telegram.GenerateMention(target), telegram.GenerateMention(msg.From)),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+
+	// Таймер новичка и отложенная проверка забаненному больше не нужны
+	h.userHandler.RemoveUserFromTimers(ctx, b, msg.Chat.ID, target.ID)
+	if err := h.ChatMemberUseCase.Left(ctx, msg.Chat.ID, target.ID, entity.MemberStatusBanned); err != nil {
+		h.logger.Error(ctx, "handleBan: track member error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+	}
+
+	h.logger.Info(ctx, "handleBan: ban",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"for", telegram.UserForLogger(target),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	text := p.Markdown("ban.done", telegram.GenerateMention(target), telegram.GenerateMention(msg.From))
+	if reason := strings.Join(rest, " "); reason != "" {
+		text += p.Markdown("ban.reason", telegram.EscapeMarkdown(reason))
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   text,
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
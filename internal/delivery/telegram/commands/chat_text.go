@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TextCallbackPrefix - кнопки превью правил и FAQ: text:save:<id>, text:cancel:<id>
+const TextCallbackPrefix = "text:"
+
+// chatTextKind - чем правила и FAQ отличаются для пользователя. Тексты - ключи каталога.
+type chatTextKind struct {
+	Capability entity.Capability
+	Header     string // после упоминания того, кто спросил
+	Missing    string // %s - упоминание
+	Empty      string
+	Saved      string
+}
+
+var chatTextKinds = map[string]chatTextKind{
+	entity.ChatTextRules: {
+		Capability: entity.CapEditRules,
+		Header:     "rules.header",
+		Missing:    "rules.missing",
+		Empty:      "rules.empty",
+		Saved:      "rules.saved",
+	},
+	entity.ChatTextFaq: {
+		Capability: entity.CapEditFaq,
+		Header:     "faq.text.header",
+		Missing:    "faq.text.missing",
+		Empty:      "faq.text.empty",
+		Saved:      "faq.text.saved",
+	},
+}
+
+// sendChatText показывает правила или FAQ так, как их записал админ
+func (h *CommandHandler) sendChatText(ctx context.Context, b *bot.Bot, msg *models.Message, kind string) {
+	info := chatTextKinds[kind]
+	p := h.printer(msg)
+	stored, exists := h.chatCache.GetText(msg.Chat.ID, kind)
+	if !exists {
+		h.logger.Debug(ctx, "sendChatText: text not exists", "kind", kind, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.Markdown(info.Missing, telegram.GenerateMention(msg.From)),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+
+	text, err := telegram.RichTextFromEntity(stored)
+	if err == nil {
+		_, err = telegram.SendRichText(ctx, b, msg.Chat.ID, msg.MessageThreadID, msg.ID, text.WithMention(msg.From, p.T(info.Header)), nil)
+	}
+	if err != nil {
+		h.logger.Error(ctx, "sendChatText: send text error",
+			"kind", kind,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("error.generic"),
+		})
+		return
+	}
+	h.logger.Debug(ctx, "sendChatText: send text", "kind", kind, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+}
+
+// previewChatText берёт текст после команды или сообщение, на которое ответили,
+// и показывает автору, как это будет выглядеть. Сохраняется только после кнопки.
+func (h *CommandHandler) previewChatText(ctx context.Context, b *bot.Bot, msg *models.Message, kind string) {
+	info := chatTextKinds[kind]
+	p := h.printer(msg)
+	text := telegram.RichTextFromCommand(msg)
+	if text.IsEmpty() && msg.ReplyToMessage != nil && msg.ReplyToMessage.ID != msg.ReplyToMessage.MessageThreadID {
+		text = telegram.RichTextFromMessage(msg.ReplyToMessage)
+	}
+	if text.IsEmpty() {
+		h.logger.Debug(ctx, "previewChatText: empty text", "kind", kind, "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T(info.Empty),
+		})
+		return
+	}
+
+	id := h.textDrafts.Add(&textDraft{
+		ChatID:   msg.Chat.ID,
+		ThreadID: msg.MessageThreadID,
+		Kind:     kind,
+		AuthorID: msg.From.ID,
+		Text:     text,
+	})
+	markup := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: p.T("draft.save"), CallbackData: TextCallbackPrefix + "save:" + id},
+			{Text: p.T("draft.cancel"), CallbackData: TextCallbackPrefix + "cancel:" + id},
+		}},
+	}
+	_, err := telegram.SendRichText(ctx, b, msg.Chat.ID, msg.MessageThreadID, msg.ID, text.WithMention(msg.From, p.T(info.Header)), markup)
+	if err != nil {
+		h.textDrafts.Delete(id)
+		h.logger.Error(ctx, "previewChatText: send preview error",
+			"kind", kind,
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("draft.preview_failed"),
+		})
+		return
+	}
+	h.logger.Debug(ctx, "previewChatText: preview sent", "kind", kind, "draft", id, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+}
+
+// HandleTextCallback обрабатывает кнопки под превью правил и FAQ
+func (h *CommandHandler) HandleTextCallback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	action, id, _ := strings.Cut(strings.TrimPrefix(query.Data, TextCallbackPrefix), ":")
+	var chatID int64
+	if query.Message.Message != nil {
+		chatID = query.Message.Message.Chat.ID
+	}
+	p := h.i18n.For(chatID, &query.From)
+	answer := func(key string, alert bool) {
+		b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            p.T(key),
+			ShowAlert:       alert,
+		})
+	}
+
+	draft, ok := h.textDrafts.Get(id)
+	if !ok {
+		h.logger.Debug(ctx, "HandleTextCallback: draft not found", "data", query.Data, "user", telegram.UserForLogger(&query.From))
+		answer("draft.expired", true)
+		h.removeDraftKeyboard(ctx, b, query)
+		return
+	}
+	if query.From.ID != draft.AuthorID {
+		answer("draft.not_yours", true)
+		return
+	}
+
+	switch action {
+	case "cancel":
+		h.textDrafts.Delete(id)
+		h.removeDraftKeyboard(ctx, b, query)
+		answer("draft.canceled", false)
+		h.logger.Debug(ctx, "HandleTextCallback: draft canceled", "kind", draft.Kind, "user", telegram.UserForLogger(&query.From), "chat_id", draft.ChatID)
+		return
+	case "save":
+	default:
+		answer("error.unknown_button", false)
+		return
+	}
+
+	info := chatTextKinds[draft.Kind]
+	// За время превью права могли и забрать
+	if err := h.PermissionUseCase.Authorize(ctx, draft.ChatID, query.From.ID, info.Capability); err != nil {
+		h.logger.Debug(ctx, "HandleTextCallback: permission denied", "kind", draft.Kind, "user", telegram.UserForLogger(&query.From), "chat_id", draft.ChatID, "err", err)
+		answer("draft.forbidden", true)
+		return
+	}
+
+	text, err := draft.Text.ToEntity(draft.ChatID, draft.Kind, query.From.ID)
+	if err == nil {
+		err = h.ChatTextUseCase.Save(ctx, text)
+	}
+	if err != nil {
+		h.logger.Error(ctx, "HandleTextCallback: save text error", "kind", draft.Kind, "user", telegram.UserForLogger(&query.From), "chat_id", draft.ChatID, "err", err)
+		answer("error.generic", true)
+		return
+	}
+	h.textDrafts.Delete(id)
+	h.chatCache.SetText(text)
+	h.removeDraftKeyboard(ctx, b, query)
+	answer("draft.saved", false)
+	h.logger.Info(ctx, "HandleTextCallback: text saved", "kind", draft.Kind, "user", telegram.UserForLogger(&query.From), "chat_id", draft.ChatID)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          draft.ChatID,
+		MessageThreadID: draft.ThreadID,
+		Text:            p.T(info.Saved),
+	})
+}
+
+// removeDraftKeyboard убирает кнопки, превью остаётся как образец
+func (h *CommandHandler) removeDraftKeyboard(ctx context.Context, b *bot.Bot, query *models.CallbackQuery) {
+	if query.Message.Message == nil {
+		return
+	}
+	b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:    query.Message.Message.Chat.ID,
+		MessageID: query.Message.Message.ID,
+	})
+}
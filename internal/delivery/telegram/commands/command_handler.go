@@ -1,67 +1,101 @@
 package commands
 
 import (
-	"context"
+	"morty-smith-34-c/internal/app/entity"
 	"morty-smith-34-c/internal/app/usecase"
 	"morty-smith-34-c/internal/delivery/telegram"
+	"morty-smith-34-c/internal/school"
 	"morty-smith-34-c/internal/storage/cache"
 	"morty-smith-34-c/pkg/logger"
-	"strings"
+	"time"
 
-	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
 type CommandHandler struct {
-	ChatUseCase *usecase.ChatUseCase // Логика работы с чатами
-	UserUseCase *usecase.UserUseCase // Логика проверки пользователей
-	chatCache   *cache.ChatCache
-	userHandler *telegram.UserHandler
-	logger      *logger.Logger
+	ChatUseCase       *usecase.ChatUseCase       // Логика работы с чатами
+	UserUseCase       *usecase.UserUseCase       // Логика проверки пользователей
+	PermissionUseCase *usecase.PermissionUseCase // Права ролей в чатах
+	NightModeUseCase  *usecase.NightModeUseCase  // Расписания ночного режима
+	MuteUseCase       *usecase.MuteUseCase       // Реестр мутов
+	RoleSyncUseCase   *usecase.RoleSyncUseCase   // Синхронизация ролей с админами Telegram
+	ChatMemberUseCase *usecase.ChatMemberUseCase // Кто в каких чатах состоит
+	ChatTextUseCase   *usecase.ChatTextUseCase   // Правила и FAQ с разметкой
+	FaqUseCase        *usecase.FaqUseCase        // База знаний: вопросы и ответы
+	chatCache         *cache.ChatCache
+	userCache         *cache.UserCache
+	userHandler       *telegram.UserHandler
+	adminSyncer       *telegram.AdminSyncer
+	schoolClient      *school.Client
+	profileCache      *cache.ProfileCache
+	templates         *telegram.TemplateRenderer
+	i18n              *telegram.Localizer
+	textDrafts        *textDrafts // Правила и FAQ, ждущие подтверждения
+	logger            *logger.Logger
 }
 
-func NewCommandHandler(log *logger.Logger, chatUseCase *usecase.ChatUseCase, userUseCase *usecase.UserUseCase, chatCache *cache.ChatCache, userHandler *telegram.UserHandler) *CommandHandler {
+func NewCommandHandler(log *logger.Logger, chatUseCase *usecase.ChatUseCase, userUseCase *usecase.UserUseCase, permissionUseCase *usecase.PermissionUseCase, nightModeUseCase *usecase.NightModeUseCase, muteUseCase *usecase.MuteUseCase, roleSyncUseCase *usecase.RoleSyncUseCase, chatMemberUseCase *usecase.ChatMemberUseCase, chatTextUseCase *usecase.ChatTextUseCase, faqUseCase *usecase.FaqUseCase, chatCache *cache.ChatCache, userCache *cache.UserCache, userHandler *telegram.UserHandler, adminSyncer *telegram.AdminSyncer, schoolClient *school.Client, profileCache *cache.ProfileCache, templates *telegram.TemplateRenderer, localizer *telegram.Localizer) *CommandHandler {
 	return &CommandHandler{
-		ChatUseCase: chatUseCase,
-		UserUseCase: userUseCase,
-		chatCache:   chatCache,
-		userHandler: userHandler,
-		logger:      log,
+		ChatUseCase:       chatUseCase,
+		UserUseCase:       userUseCase,
+		PermissionUseCase: permissionUseCase,
+		NightModeUseCase:  nightModeUseCase,
+		MuteUseCase:       muteUseCase,
+		RoleSyncUseCase:   roleSyncUseCase,
+		ChatMemberUseCase: chatMemberUseCase,
+		ChatTextUseCase:   chatTextUseCase,
+		FaqUseCase:        faqUseCase,
+		chatCache:         chatCache,
+		userCache:         userCache,
+		userHandler:       userHandler,
+		adminSyncer:       adminSyncer,
+		schoolClient:      schoolClient,
+		profileCache:      profileCache,
+		templates:         templates,
+		i18n:              localizer,
+		textDrafts:        newTextDrafts(15 * time.Minute),
+		logger:            log,
 	}
 }
 
-func (h *CommandHandler) HandleCommand(ctx context.Context, b *bot.Bot, msg *models.Message) {
-	args := strings.Fields(msg.Text)
-	if len(args) == 0 {
-		return
-	}
-
-	// Проверяем роль пользователя
-	if args[0] == "/morty_come_here" || args[0] == "/morty_id_topic_here" || args[0] == "/morty_rules" || args[0] == "/morty_faq" {
-		err := h.UserUseCase.CheckRole(ctx, msg.From.ID, []string{"superadmin"})
-		if err != nil {
-			return
-		}
-	}
-	if args[0] == "/mute" || args[0] == "/unmute" {
-		err := h.UserUseCase.CheckRole(ctx, msg.From.ID, []string{"moder", "admin", "superadmin"})
-		if err != nil {
-			return
-		}
+// Commands описывает все команды бота для роутера
+func (h *CommandHandler) Commands() []telegram.Command {
+	groups := []models.ChatType{models.ChatTypeGroup, models.ChatTypeSupergroup}
+	return []telegram.Command{
+		{Name: "morty_come_here", Capability: entity.CapActivateChat, ChatTypes: groups, Handler: h.handleMortyComeHere},
+		{Name: "morty_id_topic_here", Capability: entity.CapSetTopic, ChatTypes: groups, Handler: h.handleMortyIdTopicHere},
+		{Name: "morty_rules", Capability: entity.CapEditRules, ChatTypes: groups, Handler: h.handleMortyRules},
+		{Name: "morty_faq", Capability: entity.CapEditFaq, ChatTypes: groups, Handler: h.handleMortyFaq},
+		{Name: "faq_add", Capability: entity.CapEditFaq, ChatTypes: groups, Handler: h.handleFaqAdd},
+		{Name: "faq_edit", Capability: entity.CapEditFaq, ChatTypes: groups, MinArgs: 1, Handler: h.handleFaqEdit},
+		{Name: "faq_del", Capability: entity.CapEditFaq, ChatTypes: groups, MinArgs: 1, Handler: h.handleFaqDel},
+		{Name: "faq_pattern", Capability: entity.CapEditFaq, ChatTypes: groups, MinArgs: 1, Handler: h.handleFaqPattern},
+		{Name: "faq_list", Capability: entity.CapEditFaq, ChatTypes: groups, Handler: h.handleFaqList},
+		{Name: "morty_slowmode", Capability: entity.CapManageChat, ChatTypes: groups, MinArgs: 1, Handler: h.handleMortySlowMode},
+		{Name: "morty_nightmode", Capability: entity.CapManageChat, ChatTypes: groups, MinArgs: 1, Handler: h.handleMortyNightMode},
+		{Name: "morty_autofaq", Capability: entity.CapManageChat, ChatTypes: groups, MinArgs: 1, Handler: h.handleMortyAutoFaq},
+		{Name: "morty_template", Capability: entity.CapManageChat, ChatTypes: groups, Handler: h.handleMortyTemplate},
+		{Name: "morty_lang", Capability: entity.CapManageChat, ChatTypes: groups, MinArgs: 1, Handler: h.handleMortyLang},
+		{Name: "morty_perm", Capability: entity.CapManagePermissions, ChatTypes: groups, Handler: h.handleMortyPerm},
+		{Name: "morty_sync", Capability: entity.CapManageRoles, ChatTypes: groups, Handler: h.handleMortySync},
+		{Name: "morty_stats", Capability: entity.CapViewStats, ChatTypes: groups, Handler: h.handleMortyStats},
+		{Name: "mute", Capability: entity.CapMute, ChatTypes: groups, Handler: h.handleMute},
+		{Name: "unmute", Capability: entity.CapMute, ChatTypes: groups, Handler: h.handleUnmute},
+		{Name: "ban", Capability: entity.CapBan, ChatTypes: groups, Handler: h.handleBan},
+		{Name: "unban", Capability: entity.CapBan, ChatTypes: groups, Handler: h.handleUnban},
+		{Name: "muted", Capability: entity.CapViewMutes, ChatTypes: groups, Handler: h.handleMuted},
+		{Name: "save", Capability: entity.CapSaveUser, ChatTypes: groups, Handler: h.handleSave},
+		{Name: "role", Capability: entity.CapManageRoles, ChatTypes: groups, Handler: h.handleRole},
+		{Name: "whois", Capability: entity.CapWhois, ChatTypes: groups, Handler: h.handleWhois},
+		{Name: "who", Capability: entity.CapWhois, ChatTypes: groups, MinArgs: 1, Handler: h.handleWho},
+		{Name: "history", Capability: entity.CapWhois, ChatTypes: groups, Handler: h.handleHistory},
+		{Name: "me", Handler: h.handleMe},
+		{Name: "faq", ChatTypes: groups, Handler: h.handleFaq},
+		{Name: "rules", ChatTypes: groups, Handler: h.handleRules},
 	}
+}
 
-	switch args[0] {
-	case "/mute":
-		h.handleMute(ctx, b, msg, args)
-	case "/unmute":
-		h.handleUnmute(ctx, b, msg)
-	case "/morty_faq":
-		h.handleMortyFaq(ctx, b, msg, args)
-	case "/morty_rules":
-		h.handleMortyRules(ctx, b, msg, args)
-	case "/morty_come_here":
-		h.handleMortyComeHere(ctx, b, msg, args)
-	case "/morty_id_topic_here":
-		h.handleMortyIdTopicHere(ctx, b, msg)
-	}
+// printer - тексты на языке, которым отвечать автору сообщения
+func (h *CommandHandler) printer(msg *models.Message) telegram.Printer {
+	return h.i18n.For(msg.Chat.ID, msg.From)
 }
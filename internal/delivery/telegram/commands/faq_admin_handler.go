@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// faqListLimit - длиннее список не влезет в одно сообщение
+const faqListLimit = 3500
+
+var (
+	errFaqSyntax            = errors.New("faq syntax")
+	errFaqUnsupportedAnswer = errors.New("faq answer message has no text or supported media")
+)
+
+// handleFaqAdd добавляет вопрос: /faq_add [global] вопрос | теги | ключевые слова | ответ.
+// Ответ можно не писать, а ответить командой на сообщение с ним.
+func (h *CommandHandler) handleFaqAdd(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	payload := telegram.RichTextFromCommand(msg)
+	entry := &entity.FaqEntry{ChatID: &msg.Chat.ID}
+	if len(args) > 1 && strings.EqualFold(args[1], "global") {
+		entry.ChatID = nil
+		payload = skipFirstWord(payload)
+	}
+
+	if err := parseFaqEntry(entry, payload, msg); err != nil {
+		h.replyFaqParseError(ctx, b, msg, err)
+		return
+	}
+	if err := h.FaqUseCase.Add(ctx, msg.From.ID, entry); err != nil {
+		h.logger.Debug(ctx, "handleFaqAdd: add entry error", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, faqErrorText(p, err))
+		return
+	}
+	h.logger.Info(ctx, "handleFaqAdd: entry added", "entry", entry.ID, "global", entry.IsGlobal(), "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	h.replyFaq(ctx, b, msg, p.T("faq.added", entry.ID))
+}
+
+// handleFaqEdit заменяет вопрос целиком: /faq_edit <номер> вопрос | теги | ключевые слова | ответ
+func (h *CommandHandler) handleFaqEdit(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	id, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		h.replyFaqUsage(ctx, b, msg)
+		return
+	}
+	entry := &entity.FaqEntry{ID: id}
+	if err := parseFaqEntry(entry, skipFirstWord(telegram.RichTextFromCommand(msg)), msg); err != nil {
+		h.replyFaqParseError(ctx, b, msg, err)
+		return
+	}
+	if err := h.FaqUseCase.Update(ctx, msg.Chat.ID, msg.From.ID, entry); err != nil {
+		h.logger.Debug(ctx, "handleFaqEdit: update entry error", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, faqErrorText(p, err))
+		return
+	}
+	h.logger.Info(ctx, "handleFaqEdit: entry updated", "entry", entry.ID, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	h.replyFaq(ctx, b, msg, p.T("faq.updated", entry.ID))
+}
+
+// handleFaqDel удаляет вопрос: /faq_del <номер>
+func (h *CommandHandler) handleFaqDel(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	id, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		h.replyFaq(ctx, b, msg, p.T("faq.need_id"))
+		return
+	}
+	if err := h.FaqUseCase.Delete(ctx, msg.Chat.ID, msg.From.ID, id); err != nil {
+		h.logger.Debug(ctx, "handleFaqDel: delete entry error", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, faqErrorText(p, err))
+		return
+	}
+	h.logger.Info(ctx, "handleFaqDel: entry deleted", "entry", id, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	h.replyFaq(ctx, b, msg, p.T("faq.deleted", id))
+}
+
+// handleFaqList показывает все вопросы с номерами для /faq_edit и /faq_del
+func (h *CommandHandler) handleFaqList(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	entries, err := h.FaqUseCase.List(ctx, msg.Chat.ID)
+	if err != nil {
+		h.logger.Error(ctx, "handleFaqList: list entries error", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, faqErrorText(p, err))
+		return
+	}
+	if len(entries) == 0 {
+		h.replyFaq(ctx, b, msg, p.T("faq.list.empty"))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("faq.list.title"))
+	for i, entry := range entries {
+		line := fmt.Sprintf("\n#%d ", entry.ID)
+		if entry.IsGlobal() {
+			line += "🌍 "
+		}
+		line += entry.Question
+		if entry.Tags != "" {
+			line += " [" + entry.Tags + "]"
+		}
+		if sb.Len()+len(line) > faqListLimit {
+			sb.WriteString("\n\n" + p.N("faq.list.more", len(entries)-i))
+			break
+		}
+		sb.WriteString(line)
+	}
+	h.replyFaq(ctx, b, msg, sb.String())
+}
+
+// handleFaqPattern задаёт регулярки для автоответа, по одной на строку после номера.
+// /faq_pattern <номер> off - убрать все.
+func (h *CommandHandler) handleFaqPattern(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	id, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		h.replyFaq(ctx, b, msg, p.T("faq.need_id"))
+		return
+	}
+
+	var patterns []string
+	if len(args) < 3 || !strings.EqualFold(args[2], "off") {
+		for _, line := range strings.Split(skipFirstWord(telegram.RichTextFromCommand(msg)).Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				patterns = append(patterns, line)
+			}
+		}
+	}
+	if err := h.FaqUseCase.SetPatterns(ctx, msg.Chat.ID, msg.From.ID, id, patterns); err != nil {
+		h.logger.Debug(ctx, "handleFaqPattern: set patterns error", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, faqErrorText(p, err))
+		return
+	}
+	h.logger.Info(ctx, "handleFaqPattern: patterns updated", "entry", id, "patterns", len(patterns), "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	if len(patterns) == 0 {
+		h.replyFaq(ctx, b, msg, p.T("faq.patterns.removed", id))
+		return
+	}
+	h.replyFaq(ctx, b, msg, p.N("faq.patterns.saved", len(patterns), id))
+}
+
+func (h *CommandHandler) replyFaq(ctx context.Context, b *bot.Bot, msg *models.Message, text string) {
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            text,
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID},
+	})
+}
+
+func (h *CommandHandler) replyFaqUsage(ctx context.Context, b *bot.Bot, msg *models.Message) {
+	h.replyFaq(ctx, b, msg, h.printer(msg).T("faq.usage"))
+}
+
+// replyFaqParseError объясняет, почему не получилось разобрать вопрос
+func (h *CommandHandler) replyFaqParseError(ctx context.Context, b *bot.Bot, msg *models.Message, err error) {
+	if errors.Is(err, errFaqUnsupportedAnswer) {
+		h.logger.Debug(ctx, "replyFaqParseError: unsupported answer message", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyFaq(ctx, b, msg, h.printer(msg).T("faq.error.unsupported_answer"))
+		return
+	}
+	h.replyFaqUsage(ctx, b, msg)
+}
+
+// parseFaqEntry разбирает "вопрос | теги | ключевые слова | ответ".
+// Если команда - ответ на сообщение, ответ берётся из него вместе с разметкой и медиа.
+func parseFaqEntry(entry *entity.FaqEntry, payload telegram.RichText, msg *models.Message) error {
+	// Ответ идёт последним, в нём самом могут быть "|"
+	var bounds [][2]int
+	start := 0
+	for len(bounds) < 3 {
+		i := strings.Index(payload.Text[start:], "|")
+		if i < 0 {
+			break
+		}
+		bounds = append(bounds, [2]int{start, start + i})
+		start += i + 1
+	}
+	bounds = append(bounds, [2]int{start, len(payload.Text)})
+
+	var answer telegram.RichText
+	switch {
+	case len(bounds) == 4:
+		answer = payload.Slice(bounds[3][0], bounds[3][1])
+	case msg.ReplyToMessage != nil && msg.ReplyToMessage.ID != msg.ReplyToMessage.MessageThreadID:
+		answer = telegram.RichTextFromMessage(msg.ReplyToMessage)
+		// Стикеры, голосовые и опросы в ответ не сохранить
+		if answer.IsEmpty() {
+			return errFaqUnsupportedAnswer
+		}
+	default:
+		return errFaqSyntax
+	}
+
+	fields := make([]string, 3)
+	for i := 0; i < len(bounds) && i < 3; i++ {
+		fields[i] = payload.Slice(bounds[i][0], bounds[i][1]).Text
+	}
+	entry.Question = fields[0]
+	entry.Tags = fields[1]
+	entry.Keywords = fields[2]
+	entry.Answer = answer.Text
+	entry.AnswerEntities = nil
+	entry.MediaType, entry.MediaFileID = nil, nil
+	if answer.MediaFileID != "" {
+		entry.MediaType = &answer.MediaType
+		entry.MediaFileID = &answer.MediaFileID
+	}
+	if len(answer.Entities) > 0 {
+		data, err := json.Marshal(answer.Entities)
+		if err != nil {
+			return err
+		}
+		entities := string(data)
+		entry.AnswerEntities = &entities
+	}
+	return nil
+}
+
+// skipFirstWord отрезает первое слово вместе с разметкой
+func skipFirstWord(text telegram.RichText) telegram.RichText {
+	end := strings.IndexFunc(text.Text, unicode.IsSpace)
+	if end < 0 {
+		return telegram.RichText{}
+	}
+	return text.Slice(end, len(text.Text))
+}
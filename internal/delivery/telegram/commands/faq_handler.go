@@ -2,34 +2,259 @@ package commands
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
 	"morty-smith-34-c/internal/delivery/telegram"
+	"strconv"
+	"strings"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
-func (h *CommandHandler) FaqHandle(ctx context.Context, b *bot.Bot, msg *models.Message) {
-	faq, exists := h.chatCache.GetFaq(msg.Chat.ID)
-	if !exists {
-		h.logger.Debug(ctx, "FaqHandle: faq not exists", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+// FaqCallbackPrefix - навигация по FAQ: faq:home, faq:t:<тег>, faq:u (без тега), faq:e:<id>
+const FaqCallbackPrefix = "faq:"
+
+const (
+	// faqSureScore - с такой уверенностью сразу показываем ответ
+	faqSureScore = 0.8
+	// faqSureGap - насколько лучший вопрос должен обгонять второй, чтобы не переспрашивать
+	faqSureGap = 0.15
+	// faqMaxSuggestions - сколько вопросов предлагаем, если не уверены
+	faqMaxSuggestions = 5
+	// faqButtonLen - длиннее вопрос на кнопке не поместится
+	faqButtonLen = 40
+)
+
+// handleFaq handles the /faq command: без аргументов - FAQ чата и темы, с аргументами - поиск
+func (h *CommandHandler) handleFaq(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	if topic := telegram.CommandPayload(msg.Text); topic != "" {
+		h.searchFaq(ctx, b, msg, topic)
+		return
+	}
+
+	entries, err := h.FaqUseCase.List(ctx, msg.Chat.ID)
+	if err != nil {
+		h.logger.Error(ctx, "handleFaq: list entries error", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+	}
+	if _, exists := h.chatCache.GetText(msg.Chat.ID, entity.ChatTextFaq); exists || len(entries) == 0 {
+		h.sendChatText(ctx, b, msg, entity.ChatTextFaq)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	text, markup := faqHomeView(p, entries)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            text,
+		ReplyMarkup:     markup,
+	})
+}
+
+// searchFaq показывает ответ, если уверен, иначе предлагает похожие вопросы
+func (h *CommandHandler) searchFaq(ctx context.Context, b *bot.Bot, msg *models.Message, topic string) {
+	p := h.printer(msg)
+	matches, err := h.FaqUseCase.Search(ctx, msg.Chat.ID, topic)
+	if err != nil {
+		h.logger.Error(ctx, "searchFaq: search error", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("error.generic"),
+		})
+		return
+	}
+	h.logger.Debug(ctx, "searchFaq: search", "text", msg.Text, "matches", len(matches), "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+
+	if len(matches) == 0 {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("Ох\\, %s\\. Я не могу тебе помочь 😭", telegram.GenerateMention(msg.From)),
-			ReplyParameters: &models.ReplyParameters{
-				MessageID: msg.ID,
-			},
-			ParseMode: models.ParseModeMarkdown,
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("faq.search.nothing", topic),
+			ReplyParameters: &models.ReplyParameters{MessageID: msg.ID},
 		})
 		return
 	}
-	h.logger.Debug(ctx, "FaqHandle: send faq", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+
+	if faqIsSure(matches) {
+		text, markup, err := faqEntryView(p, matches[0].Entry)
+		if err == nil {
+			_, err = telegram.SendRichText(ctx, b, msg.Chat.ID, msg.MessageThreadID, msg.ID, text, markup)
+		}
+		if err != nil {
+			h.logger.Error(ctx, "searchFaq: send answer error", "entry", matches[0].Entry.ID, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		}
+		return
+	}
+
+	rows := make([][]models.InlineKeyboardButton, 0, faqMaxSuggestions+1)
+	for i, match := range matches {
+		if i == faqMaxSuggestions {
+			break
+		}
+		rows = append(rows, []models.InlineKeyboardButton{faqEntryButton(match.Entry)})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{faqHomeButton(p)})
 	b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: msg.Chat.ID,
-		Text:   fmt.Sprintf("%s\\, ответы на часто задаваемые вопросы\\:\n%s", telegram.GenerateMention(msg.From), telegram.EscapeMarkdown(faq)),
-		ReplyParameters: &models.ReplyParameters{
-			MessageID: msg.ID,
-		},
-		ParseMode: models.ParseModeMarkdown,
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            p.T("faq.search.maybe"),
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID},
+		ReplyMarkup:     &models.InlineKeyboardMarkup{InlineKeyboard: rows},
 	})
 }
+
+// HandleFaqCallback листает темы и вопросы FAQ в том же сообщении
+func (h *CommandHandler) HandleFaqCallback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	defer b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+
+	message := query.Message.Message
+	if message == nil {
+		return
+	}
+	p := h.i18n.For(message.Chat.ID, &query.From)
+	entries, err := h.FaqUseCase.List(ctx, message.Chat.ID)
+	if err != nil {
+		h.logger.Error(ctx, "HandleFaqCallback: list entries error", "data", query.Data, "user", telegram.UserForLogger(&query.From), "chat", telegram.ChatForLogger(message.Chat), "err", err)
+		return
+	}
+
+	var view telegram.RichText
+	var markup *models.InlineKeyboardMarkup
+	action, value, _ := strings.Cut(strings.TrimPrefix(query.Data, FaqCallbackPrefix), ":")
+	switch action {
+	case "t":
+		view.Text, markup = faqCategoryView(p, entries, value)
+	case "u":
+		view.Text, markup = faqCategoryView(p, entries, "")
+	case "e":
+		id, _ := strconv.ParseInt(value, 10, 64)
+		entry, err := h.FaqUseCase.Get(ctx, message.Chat.ID, id)
+		if err != nil {
+			h.logger.Debug(ctx, "HandleFaqCallback: entry not found", "data", query.Data, "user", telegram.UserForLogger(&query.From), "chat", telegram.ChatForLogger(message.Chat), "err", err)
+			view.Text, markup = faqHomeView(p, entries)
+			break
+		}
+		view, markup, err = faqEntryView(p, entry)
+		if err != nil {
+			h.logger.Error(ctx, "HandleFaqCallback: render entry error", "entry", entry.ID, "chat", telegram.ChatForLogger(message.Chat), "err", err)
+			return
+		}
+	default:
+		view.Text, markup = faqHomeView(p, entries)
+	}
+
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      message.Chat.ID,
+		MessageID:   message.ID,
+		Text:        view.Text,
+		Entities:    view.Entities,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		h.logger.Debug(ctx, "HandleFaqCallback: edit message error", "data", query.Data, "user", telegram.UserForLogger(&query.From), "chat", telegram.ChatForLogger(message.Chat), "err", err)
+	}
+}
+
+// faqIsSure - лучший вопрос подходит хорошо и заметно лучше остальных
+func faqIsSure(matches []entity.FaqMatch) bool {
+	if matches[0].Score < faqSureScore {
+		return false
+	}
+	return len(matches) == 1 || matches[0].Score-matches[1].Score >= faqSureGap
+}
+
+// faqHomeView - список тем, а если тем нет совсем, то сразу вопросы
+func faqHomeView(p telegram.Printer, entries []*entity.FaqEntry) (string, *models.InlineKeyboardMarkup) {
+	categories := usecase.FaqCategories(entries)
+	if len(categories) == 0 {
+		return faqQuestionsView(p, p.T("faq.nav.questions"), entries, false)
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	var row []models.InlineKeyboardButton
+	for _, tag := range categories {
+		row = append(row, models.InlineKeyboardButton{Text: "📂 " + tag, CallbackData: FaqCallbackPrefix + "t:" + tag})
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if len(usecase.FaqInCategory(entries, "")) > 0 {
+		rows = append(rows, []models.InlineKeyboardButton{{Text: p.T("faq.nav.untagged"), CallbackData: FaqCallbackPrefix + "u"}})
+	}
+	return p.T("faq.nav.home"), &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// faqCategoryView - вопросы одной темы, пустой тег - вопросы без темы
+func faqCategoryView(p telegram.Printer, entries []*entity.FaqEntry, tag string) (string, *models.InlineKeyboardMarkup) {
+	title := p.T("faq.nav.untagged") + ":"
+	if tag != "" {
+		title = "📂 " + tag + ":"
+	}
+	return faqQuestionsView(p, title, usecase.FaqInCategory(entries, tag), true)
+}
+
+func faqQuestionsView(p telegram.Printer, title string, entries []*entity.FaqEntry, withBack bool) (string, *models.InlineKeyboardMarkup) {
+	rows := make([][]models.InlineKeyboardButton, 0, len(entries)+1)
+	for _, entry := range entries {
+		rows = append(rows, []models.InlineKeyboardButton{faqEntryButton(entry)})
+	}
+	if withBack {
+		rows = append(rows, []models.InlineKeyboardButton{faqHomeButton(p)})
+	}
+	if len(entries) == 0 {
+		title += "\n" + p.T("faq.nav.empty")
+	}
+	return title, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// faqEntryView - вопрос жирным, под ним ответ с разметкой
+func faqEntryView(p telegram.Printer, entry *entity.FaqEntry) (telegram.RichText, *models.InlineKeyboardMarkup, error) {
+	view, err := telegram.RichTextFromFaqEntry(entry)
+	if err != nil {
+		return telegram.RichText{}, nil, err
+	}
+
+	back := faqHomeButton(p)
+	if tags := entry.TagList(); len(tags) > 0 {
+		back = models.InlineKeyboardButton{Text: "⬅️ " + tags[0], CallbackData: FaqCallbackPrefix + "t:" + tags[0]}
+	}
+	return view, &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{back}}}, nil
+}
+
+func faqEntryButton(entry *entity.FaqEntry) models.InlineKeyboardButton {
+	text := []rune(entry.Question)
+	if len(text) > faqButtonLen {
+		text = append(text[:faqButtonLen-1], '…')
+	}
+	return models.InlineKeyboardButton{Text: string(text), CallbackData: FaqCallbackPrefix + "e:" + strconv.FormatInt(entry.ID, 10)}
+}
+
+func faqHomeButton(p telegram.Printer) models.InlineKeyboardButton {
+	return models.InlineKeyboardButton{Text: p.T("faq.nav.all"), CallbackData: FaqCallbackPrefix + "home"}
+}
+
+// faqErrorText - что ответить админу на ошибку базы знаний
+func faqErrorText(p telegram.Printer, err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrFaqNotFound):
+		return p.T("faq.error.not_found")
+	case errors.Is(err, usecase.ErrFaqEmpty):
+		return p.T("faq.error.empty")
+	case errors.Is(err, usecase.ErrFaqBadPattern):
+		return p.T("faq.error.bad_pattern", strings.TrimPrefix(err.Error(), usecase.ErrFaqBadPattern.Error()+": "))
+	case errors.Is(err, usecase.ErrFaqTagTooLong):
+		return p.T("faq.error.tag_too_long")
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		return p.T("faq.error.global_forbidden")
+	}
+	return p.T("error.generic")
+}
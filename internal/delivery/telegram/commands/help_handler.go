@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// HelpCommand описывает /help. Ему нужен роутер, чтобы видеть все зарегистрированные команды.
+func (h *CommandHandler) HelpCommand(router *telegram.Router) telegram.Command {
+	return telegram.Command{
+		Name:    "help",
+		Aliases: []string{"start"},
+		Handler: func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+			h.handleHelp(ctx, b, msg, args, router)
+		},
+	}
+}
+
+// handleHelp показывает команды, доступные вызвавшему в этом чате, или подробности об одной
+func (h *CommandHandler) handleHelp(ctx context.Context, b *bot.Bot, msg *models.Message, args []string, router *telegram.Router) {
+	p := h.printer(msg)
+	var text string
+	if len(args) > 1 {
+		cmd, ok := router.Lookup(args[1])
+		if !ok || !h.canUse(ctx, msg, cmd) {
+			text = p.T("help.unknown", args[1])
+		} else {
+			text = formatCommandHelp(p, cmd)
+		}
+	} else {
+		var sb strings.Builder
+		sb.WriteString(p.T("help.title"))
+		for _, cmd := range router.Commands() {
+			if h.canUse(ctx, msg, cmd) {
+				sb.WriteString(fmt.Sprintf("\n/%s — %s", cmd.Name, cmd.Description(p)))
+			}
+		}
+		sb.WriteString("\n\n" + p.T("help.more"))
+		text = sb.String()
+	}
+
+	h.logger.Debug(ctx, "handleHelp: send help",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            text,
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+	})
+}
+
+// canUse проверяет, покажется ли команда в /help: она не скрыта, работает в этом чате
+// и у пользователя есть нужное право
+func (h *CommandHandler) canUse(ctx context.Context, msg *models.Message, cmd *telegram.Command) bool {
+	if cmd.Hidden || !cmd.AllowedIn(msg.Chat.Type) {
+		return false
+	}
+	if cmd.Capability == "" {
+		return true
+	}
+	return h.PermissionUseCase.Authorize(ctx, msg.Chat.ID, msg.From.ID, cmd.Capability) == nil
+}
+
+func formatCommandHelp(p telegram.Printer, cmd *telegram.Command) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/%s — %s", cmd.Name, cmd.Description(p)))
+	if usage := cmd.Usage(p); usage != "" {
+		sb.WriteString("\n" + p.T("router.example", usage))
+	}
+	if len(cmd.Aliases) > 0 {
+		sb.WriteString("\n" + p.T("help.aliases", "/"+strings.Join(cmd.Aliases, ", /")))
+	}
+	if cmd.Capability != "" {
+		sb.WriteString("\n" + p.T("help.capability", string(cmd.Capability)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleHistory handles the /history command: every nickname a user has had
+func (h *CommandHandler) handleHistory(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, _, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		h.replyTargetNotFound(ctx, b, msg, err)
+		return
+	}
+	history, err := h.UserUseCase.NickHistory(ctx, target.ID)
+	if err != nil {
+		h.logger.Error(ctx, "handleHistory: get history error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		h.replyProfile(ctx, b, msg, p.Markdown("error.oops"))
+		return
+	}
+	if len(history) == 0 {
+		h.replyProfile(ctx, b, msg, p.Markdown("history.empty", telegram.GenerateMention(target)))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.Markdown("history.title", telegram.GenerateMention(target)))
+	for _, record := range history {
+		nick := p.T("history.no_nick")
+		if record.SchoolName != nil {
+			nick = *record.SchoolName
+		}
+		// Источник ника, неизвестный каталогу, показываем как есть
+		source := record.Source
+		if key := "history.source." + record.Source; p.Has(key) {
+			source = p.T(key)
+		}
+		line := fmt.Sprintf("• %s — %s\\, %s",
+			telegram.EscapeMarkdown(record.CreatedAt.In(usecase.MoscowLocation).Format("02.01.2006 15:04")),
+			telegram.EscapeMarkdown(nick),
+			telegram.EscapeMarkdown(source),
+		)
+		if record.SetBy != nil {
+			line += " " + h.mentionByTelegramID(ctx, *record.SetBy)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	h.logger.Debug(ctx, "handleHistory: send history",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"for", telegram.UserForLogger(target),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	h.replyProfile(ctx, b, msg, sb.String())
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleMortyAutoFaq включает автоответы из FAQ: /morty_autofaq on, /morty_autofaq 0.7, /morty_autofaq off
+func (h *CommandHandler) handleMortyAutoFaq(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	if _, ok := h.chatCache.GetThreadID(msg.Chat.ID); !ok {
+		h.logger.Debug(ctx, "handleMortyAutoFaq: chat not activated", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyFaq(ctx, b, msg, p.T("error.not_activated"))
+		return
+	}
+
+	var threshold float64
+	switch strings.ToLower(args[1]) {
+	case "off":
+		threshold = 0
+	case "on":
+		threshold = usecase.DefaultAutoFaqThreshold
+	default:
+		var err error
+		threshold, err = strconv.ParseFloat(strings.ReplaceAll(args[1], ",", "."), 64)
+		if err != nil || threshold < usecase.MinAutoFaqThreshold || threshold > 1 {
+			h.logger.Debug(ctx, "handleMortyAutoFaq: wrong threshold", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+			h.replyFaq(ctx, b, msg, p.T("autofaq.bad_threshold", usecase.MinAutoFaqThreshold))
+			return
+		}
+	}
+
+	if err := h.ChatUseCase.UpdateAutoFaqThreshold(ctx, msg.Chat.ID, threshold); err != nil {
+		h.logger.Error(ctx, "handleMortyAutoFaq: update threshold error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		h.replyFaq(ctx, b, msg, p.T("error.generic"))
+		return
+	}
+	h.chatCache.SetAutoFaqThreshold(msg.Chat.ID, threshold)
+	h.logger.Info(ctx, "handleMortyAutoFaq: threshold updated", "threshold", threshold, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+
+	text := p.T("autofaq.on", threshold*100)
+	if threshold == 0 {
+		text = p.T("autofaq.off")
+	}
+	h.replyFaq(ctx, b, msg, text)
+}
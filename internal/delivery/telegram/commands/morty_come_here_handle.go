@@ -15,12 +15,13 @@ func (h *CommandHandler) handleMortyComeHere(ctx context.Context, b *bot.Bot, ms
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
 	})
+	p := h.printer(msg)
 	if len(args) < 2 {
 		h.logger.Debug(ctx, "handlerMortyComeHere: missing campus", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
 		sendMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:          msg.Chat.ID,
 			MessageThreadID: msg.MessageThreadID,
-			Text:            "О, боже мой! Укажите название кампуса, пожалуйста. Пример: /morty_come_here msk. Это ведь не так сложно, да?",
+			Text:            p.T("come_here.missing_campus"),
 		})
 		if err != nil {
 			return
@@ -48,7 +49,7 @@ func (h *CommandHandler) handleMortyComeHere(ctx context.Context, b *bot.Bot, ms
 		sendMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:          msg.Chat.ID,
 			MessageThreadID: msg.MessageThreadID,
-			Text:            "Эй, эм... кажется, произошла ошибка! Может, чат уже активирован? О-о-о, я весь в поту!",
+			Text:            p.T("come_here.failed"),
 		})
 		if err != nil {
 			return
@@ -62,10 +63,11 @@ func (h *CommandHandler) handleMortyComeHere(ctx context.Context, b *bot.Bot, ms
 		return
 	}
 	h.logger.Debug(ctx, "handlerMortyComeHere: campus created", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	h.chatCache.SetLanguage(msg.Chat.ID, telegram.DefaultLanguage)
 	sendMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:          msg.Chat.ID,
 		MessageThreadID: msg.MessageThreadID,
-		Text:            "Эй, поздравляю! Чат успешно активирован для кампуса: " + campusName + ". Ура-а-а!",
+		Text:            p.T("come_here.done", campusName),
 	})
 	if err != nil {
 		return
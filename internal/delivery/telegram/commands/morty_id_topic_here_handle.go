@@ -9,11 +9,12 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
-func (h *CommandHandler) handleMortyIdTopicHere(ctx context.Context, b *bot.Bot, msg *models.Message) {
+func (h *CommandHandler) handleMortyIdTopicHere(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
 	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
 	})
+	p := h.printer(msg)
 	err := h.ChatUseCase.UpdateThreadID(ctx, msg.Chat.ID, msg.MessageThreadID)
 	if err != nil {
 		h.logger.Error(ctx, "handleMortyIdTopicHere: save id topic error",
@@ -25,7 +26,7 @@ func (h *CommandHandler) handleMortyIdTopicHere(ctx context.Context, b *bot.Bot,
 		sendMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:          msg.Chat.ID,
 			MessageThreadID: msg.MessageThreadID,
-			Text:            "О-о-о, о нет! Я пытался назначить топик, но что-то пошло не так. Простите, ребята!",
+			Text:            p.T("topic.failed"),
 		})
 		if err != nil {
 			return
@@ -43,7 +44,7 @@ func (h *CommandHandler) handleMortyIdTopicHere(ctx context.Context, b *bot.Bot,
 	sendMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:          msg.Chat.ID,
 		MessageThreadID: msg.MessageThreadID,
-		Text:            "Эй, топик ID успешно установлен! Круто, да? Теперь я могу немного расслабиться...",
+		Text:            p.T("topic.done"),
 	})
 	if err != nil {
 		return
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleMortyLang выбирает язык Морти в чате: /morty_lang en, /morty_lang ru,
+// /morty_lang auto - каждому на языке его клиента Telegram
+func (h *CommandHandler) handleMortyLang(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	if _, ok := h.chatCache.GetThreadID(msg.Chat.ID); !ok {
+		h.logger.Debug(ctx, "handleMortyLang: chat not activated", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyFaq(ctx, b, msg, p.T("error.not_activated"))
+		return
+	}
+
+	language := strings.ToLower(args[1])
+	if language != entity.ChatLanguageAuto {
+		var ok bool
+		if language, ok = h.i18n.Match(language); !ok {
+			h.logger.Debug(ctx, "handleMortyLang: unknown language", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+			h.replyFaq(ctx, b, msg, p.T("lang.unknown", strings.Join(h.i18n.Languages(), ", ")))
+			return
+		}
+	}
+
+	if err := h.ChatUseCase.UpdateLanguage(ctx, msg.Chat.ID, language); err != nil {
+		h.logger.Error(ctx, "handleMortyLang: update language error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		h.replyFaq(ctx, b, msg, p.T("error.generic"))
+		return
+	}
+	h.chatCache.SetLanguage(msg.Chat.ID, language)
+	h.logger.Info(ctx, "handleMortyLang: language updated", "language", language, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+
+	// Отвечаем уже на новом языке
+	p = h.printer(msg)
+	if language == entity.ChatLanguageAuto {
+		h.replyFaq(ctx, b, msg, p.T("lang.auto"))
+		return
+	}
+	h.replyFaq(ctx, b, msg, p.T("lang.set"))
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func (h *CommandHandler) handleMortyNightMode(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+	})
+	p := h.printer(msg)
+	if _, ok := h.chatCache.GetThreadID(msg.Chat.ID); !ok {
+		h.logger.Debug(ctx, "handleMortyNightMode: chat not activated", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("error.not_activated"),
+		})
+		deleteAfter(ctx, b, sendMsg, time.Minute)
+		return
+	}
+
+	if args[1] == "off" {
+		if err := h.NightModeUseCase.Disable(ctx, msg.Chat.ID); err != nil {
+			h.logger.Error(ctx, "handleMortyNightMode: disable error",
+				"text", msg.Text,
+				"user", telegram.UserForLogger(msg.From),
+				"chat", telegram.ChatForLogger(msg.Chat),
+				"err", err,
+			)
+			sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:          msg.Chat.ID,
+				MessageThreadID: msg.MessageThreadID,
+				Text:            p.T("error.generic"),
+			})
+			deleteAfter(ctx, b, sendMsg, time.Minute)
+			return
+		}
+		h.logger.Info(ctx, "handleMortyNightMode: night mode disabled", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("nightmode.off"),
+		})
+		deleteAfter(ctx, b, sendMsg, time.Minute)
+		return
+	}
+
+	start, end, err := parseTimeRange(args[1])
+	restriction := entity.NightRestrictionMedia
+	if len(args) > 2 {
+		restriction = args[2]
+	}
+	if err != nil || (restriction != entity.NightRestrictionMedia && restriction != entity.NightRestrictionAll) {
+		h.logger.Debug(ctx, "handleMortyNightMode: wrong format", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("nightmode.bad_format"),
+		})
+		deleteAfter(ctx, b, sendMsg, time.Minute)
+		return
+	}
+
+	if err := h.NightModeUseCase.SetSchedule(ctx, msg.Chat.ID, start, end, restriction); err != nil {
+		h.logger.Error(ctx, "handleMortyNightMode: save schedule error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("error.generic"),
+		})
+		deleteAfter(ctx, b, sendMsg, time.Minute)
+		return
+	}
+	h.logger.Info(ctx, "handleMortyNightMode: schedule saved",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	what := p.T("nightmode.media")
+	if restriction == entity.NightRestrictionAll {
+		what = p.T("nightmode.all")
+	}
+	sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            p.T("nightmode.on", formatClock(start), formatClock(end), what),
+	})
+	deleteAfter(ctx, b, sendMsg, time.Minute)
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleMortyPerm настраивает права ролей в чате:
+// /morty_perm - показать права всех ролей
+// /morty_perm moder +save_user -mute - выдать и забрать права
+// /morty_perm moder reset - вернуть права по умолчанию
+func (h *CommandHandler) handleMortyPerm(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	if len(args) < 2 {
+		h.sendPermissions(ctx, b, msg)
+		return
+	}
+	p := h.printer(msg)
+
+	role := strings.ToLower(args[1])
+	if !usecase.IsValidRole(role) {
+		h.logger.Debug(ctx, "handleMortyPerm: wrong role", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyPerm(ctx, b, msg, p.T("perm.unknown_role"))
+		return
+	}
+	if len(args) < 3 {
+		h.replyPerm(ctx, b, msg, p.T("perm.nothing"))
+		return
+	}
+
+	if args[2] == "reset" {
+		if err := h.PermissionUseCase.ResetRole(ctx, msg.Chat.ID, role); err != nil {
+			h.logger.Info(ctx, "handleMortyPerm: reset rejected", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+			h.replyPerm(ctx, b, msg, permissionErrorText(p, err))
+			return
+		}
+		h.logger.Info(ctx, "handleMortyPerm: reset role", "role", role, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyPerm(ctx, b, msg, p.T("perm.reset", role))
+		return
+	}
+
+	for _, arg := range args[2:] {
+		if len(arg) < 2 || (arg[0] != '+' && arg[0] != '-') {
+			h.replyPerm(ctx, b, msg, p.T("perm.bad_change", arg))
+			return
+		}
+		capability := entity.Capability(strings.ToLower(arg[1:]))
+		if err := h.PermissionUseCase.SetCapability(ctx, msg.Chat.ID, role, capability, arg[0] == '+'); err != nil {
+			h.logger.Info(ctx, "handleMortyPerm: change rejected", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+			h.replyPerm(ctx, b, msg, permissionErrorText(p, err))
+			return
+		}
+	}
+	h.logger.Info(ctx, "handleMortyPerm: permissions updated", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	h.sendPermissions(ctx, b, msg)
+}
+
+// sendPermissions показывает итоговые права ролей в чате
+func (h *CommandHandler) sendPermissions(ctx context.Context, b *bot.Bot, msg *models.Message) {
+	p := h.printer(msg)
+	var sb strings.Builder
+	sb.WriteString(p.T("perm.title"))
+	for _, role := range usecase.Roles {
+		capabilities, err := h.PermissionUseCase.RoleCapabilities(ctx, msg.Chat.ID, role)
+		if err != nil {
+			h.logger.Error(ctx, "handleMortyPerm: get capabilities", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+			h.replyPerm(ctx, b, msg, p.T("error.oops"))
+			return
+		}
+		names := make([]string, 0, len(capabilities))
+		for _, c := range capabilities {
+			names = append(names, string(c))
+		}
+		if len(names) == 0 {
+			names = append(names, "—")
+		}
+		sb.WriteString(fmt.Sprintf("\n%s: %s", role, strings.Join(names, ", ")))
+	}
+	h.replyPerm(ctx, b, msg, sb.String())
+}
+
+func (h *CommandHandler) replyPerm(ctx context.Context, b *bot.Bot, msg *models.Message, text string) {
+	sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            text,
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+	})
+	deleteAfter(ctx, b, sendMsg, 2*time.Minute)
+}
+
+// permissionErrorText объясняет, почему права не поменялись
+func permissionErrorText(p telegram.Printer, err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrUnknownRole):
+		return p.T("perm.error.unknown_role")
+	case errors.Is(err, usecase.ErrImmutableRole):
+		return p.T("perm.error.immutable")
+	case errors.Is(err, usecase.ErrUnknownCapability):
+		names := make([]string, 0, len(usecase.Capabilities))
+		for _, c := range usecase.Capabilities {
+			names = append(names, string(c))
+		}
+		return p.T("perm.error.unknown_capability", strings.Join(names, ", "))
+	}
+	return p.T("error.oops")
+}
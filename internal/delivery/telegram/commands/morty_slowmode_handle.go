@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func (h *CommandHandler) handleMortySlowMode(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+	})
+	p := h.printer(msg)
+	if _, ok := h.chatCache.GetThreadID(msg.Chat.ID); !ok {
+		h.logger.Debug(ctx, "handleMortySlowMode: chat not activated", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("error.not_activated"),
+		})
+		deleteAfter(ctx, b, sendMsg, time.Minute)
+		return
+	}
+
+	delay := 0
+	if args[1] != "off" {
+		var err error
+		delay, err = strconv.Atoi(args[1])
+		if err != nil || delay < 1 || delay > telegram.MaxSlowModeDelay {
+			h.logger.Debug(ctx, "handleMortySlowMode: wrong delay", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+			sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:          msg.Chat.ID,
+				MessageThreadID: msg.MessageThreadID,
+				Text:            p.T("slowmode.bad_delay", telegram.MaxSlowModeDelay),
+			})
+			deleteAfter(ctx, b, sendMsg, time.Minute)
+			return
+		}
+	}
+
+	if err := h.ChatUseCase.UpdateSlowModeDelay(ctx, msg.Chat.ID, delay); err != nil {
+		h.logger.Error(ctx, "handleMortySlowMode: update delay error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            p.T("error.generic"),
+		})
+		deleteAfter(ctx, b, sendMsg, time.Minute)
+		return
+	}
+	h.chatCache.SetSlowModeDelay(msg.Chat.ID, delay)
+	h.logger.Info(ctx, "handleMortySlowMode: slow mode updated", "delay", delay, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+
+	text := p.T("slowmode.on", delay)
+	if delay == 0 {
+		text = p.T("slowmode.off")
+	}
+	sendMsg, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            text,
+	})
+	deleteAfter(ctx, b, sendMsg, time.Minute)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// statsPeriod - за какой период показывать движение участников
+const statsPeriod = 7 * 24 * time.Hour
+
+// handleMortyStats handles the /morty_stats command: member counts of the chat
+func (h *CommandHandler) handleMortyStats(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	stats, err := h.ChatMemberUseCase.Stats(ctx, msg.Chat.ID, time.Now().Add(-statsPeriod))
+	if err != nil {
+		h.logger.Error(ctx, "handleMortyStats: get stats error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("error.oops"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("stats.title"))
+	sb.WriteString("\n" + p.T("stats.member", stats.ByStatus[entity.MemberStatusMember]))
+	sb.WriteString("\n" + p.T("stats.pending", stats.ByStatus[entity.MemberStatusPending]))
+	sb.WriteString("\n" + p.T("stats.left", stats.ByStatus[entity.MemberStatusLeft]))
+	sb.WriteString("\n" + p.T("stats.kicked", stats.ByStatus[entity.MemberStatusKicked]))
+	sb.WriteString("\n" + p.T("stats.banned", stats.ByStatus[entity.MemberStatusBanned]))
+	sb.WriteString("\n\n" + p.T("stats.week", stats.JoinedSince, stats.LeftSince))
+	sb.WriteString("\n\n" + p.T("stats.note"))
+
+	h.logger.Debug(ctx, "handleMortyStats: send stats",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   sb.String(),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+	})
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleMortySync сверяет роли с админами Telegram:
+// /morty_sync - синхронизировать и показать отчёт
+// /morty_sync dry - только показать разницу
+// /morty_sync strict - снять и роли из /role у тех, кто в Telegram не админ (можно вместе с dry)
+// /morty_sync off [цель] - не трогать роль пользователя при синхронизации, on - вернуть
+func (h *CommandHandler) handleMortySync(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	if len(args) > 1 && (args[1] == "off" || args[1] == "on") {
+		h.handleMortySyncOptOut(ctx, b, msg, args[1] == "off", args[2:])
+		return
+	}
+
+	p := h.printer(msg)
+	opts := usecase.RoleSyncOptions{Apply: true}
+	for _, arg := range args[1:] {
+		switch arg {
+		case "dry":
+			opts.Apply = false
+		case "strict":
+			opts.RevokeManual = true
+		}
+	}
+	report, err := h.adminSyncer.SyncChat(ctx, b, msg.Chat.ID, opts)
+	if err != nil {
+		h.logger.Error(ctx, "handleMortySync: sync error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("sync.failed"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+		return
+	}
+	h.logger.Info(ctx, "handleMortySync: roles synced",
+		"apply", opts.Apply,
+		"strict", opts.RevokeManual,
+		"granted", len(report.Granted),
+		"revoked", len(report.Revoked),
+		"conflicts", len(report.Conflicts),
+		"unbacked", len(report.Unbacked),
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   h.formatSyncReport(ctx, p, report, opts.Apply),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
+
+func (h *CommandHandler) handleMortySyncOptOut(ctx context.Context, b *bot.Bot, msg *models.Message, optOut bool, args []string) {
+	p := h.printer(msg)
+	target, _, err := h.resolveTarget(ctx, msg, args)
+	if err != nil {
+		if len(args) > 0 {
+			h.replyTargetNotFound(ctx, b, msg, err)
+			return
+		}
+		target = msg.From
+	}
+	if target.ID != msg.From.ID && !h.canActOn(ctx, b, msg, target) {
+		return
+	}
+	if err := h.RoleSyncUseCase.SetOptOut(ctx, msg.Chat.ID, target.ID, optOut); err != nil {
+		h.logger.Error(ctx, "handleMortySync: opt-out error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("error.oops"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+		return
+	}
+	h.logger.Info(ctx, "handleMortySync: opt-out changed",
+		"optOut", optOut,
+		"user", telegram.UserForLogger(msg.From),
+		"for", telegram.UserForLogger(target),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	key := "sync.opted_out"
+	if !optOut {
+		key = "sync.opted_in"
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   p.Markdown(key, telegram.GenerateMention(target)),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
+
+// formatSyncReport собирает отчёт о синхронизации в MarkdownV2
+func (h *CommandHandler) formatSyncReport(ctx context.Context, p telegram.Printer, report *usecase.RoleSyncReport, apply bool) string {
+	if !report.HasChanges() && len(report.Conflicts) == 0 && len(report.Unbacked) == 0 && len(report.OptedOut) == 0 {
+		return p.Markdown("sync.in_sync")
+	}
+
+	var sb strings.Builder
+	if apply {
+		sb.WriteString(p.Markdown("sync.applied"))
+	} else {
+		sb.WriteString(p.Markdown("sync.dry"))
+	}
+	sections := []struct {
+		title   string
+		changes []usecase.RoleSyncChange
+	}{
+		{"sync.granted", report.Granted},
+		{"sync.revoked", report.Revoked},
+		{"sync.conflicts", report.Conflicts},
+		{"sync.unbacked", report.Unbacked},
+		{"sync.opted_out_list", report.OptedOut},
+	}
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+		sb.WriteString("\n*" + p.Markdown(section.title) + "*\n")
+		for _, change := range section.changes {
+			mention := h.mentionByTelegramID(ctx, change.TelegramID)
+			if change.Name != "" {
+				mention = telegram.GenerateMention(&models.User{ID: change.TelegramID, FirstName: change.Name})
+			}
+			sb.WriteString(fmt.Sprintf("• %s\\: %s → %s\n",
+				mention,
+				telegram.EscapeMarkdown(change.From),
+				telegram.EscapeMarkdown(change.To),
+			))
+		}
+	}
+	if len(report.Unbacked) > 0 {
+		sb.WriteString("\n" + p.Markdown("sync.strict_hint"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleMortyTemplate показывает и меняет тексты Морти в чате:
+// /morty_template - список, /morty_template <имя> - текст и превью,
+// /morty_template <имя> set <текст> - переопределить, /morty_template <имя> reset - вернуть как было
+func (h *CommandHandler) handleMortyTemplate(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	if len(args) < 2 {
+		h.listTemplates(ctx, b, msg)
+		return
+	}
+	p := h.printer(msg)
+	name := strings.ToLower(args[1])
+	if _, ok := telegram.LookupTemplate(name); !ok {
+		h.replyFaq(ctx, b, msg, p.T("template.unknown"))
+		return
+	}
+
+	action := ""
+	if len(args) > 2 {
+		action = strings.ToLower(args[2])
+	}
+	switch action {
+	case "":
+		h.showTemplate(ctx, b, msg, name)
+	case "set":
+		h.setTemplate(ctx, b, msg, name)
+	case "reset":
+		if err := h.templates.TemplateUseCase.Reset(ctx, msg.Chat.ID, name); err != nil {
+			h.logger.Error(ctx, "handleMortyTemplate: reset template error", "template", name, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+			h.replyFaq(ctx, b, msg, p.T("error.generic"))
+			return
+		}
+		h.templates.Invalidate(msg.Chat.ID)
+		h.logger.Info(ctx, "handleMortyTemplate: template reset", "template", name, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyFaq(ctx, b, msg, p.T("template.reset", name))
+	default:
+		h.replyFaq(ctx, b, msg, p.T("template.bad_action"))
+	}
+}
+
+func (h *CommandHandler) listTemplates(ctx context.Context, b *bot.Bot, msg *models.Message) {
+	p := h.printer(msg)
+	overridden := make(map[string]bool)
+	overrides, err := h.templates.TemplateUseCase.Overrides(ctx, msg.Chat.ID)
+	if err != nil {
+		h.logger.Error(ctx, "handleMortyTemplate: list overrides error", "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+	}
+	for _, override := range overrides {
+		overridden[override.Name] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("template.list.title"))
+	for _, info := range telegram.Templates {
+		mark := ""
+		if overridden[info.Name] {
+			mark = " ✏️"
+		}
+		fmt.Fprintf(&sb, "\n%s%s - %s", info.Name, mark, p.T("template."+info.Name+".about"))
+	}
+	sb.WriteString("\n\n" + p.T("template.list.more"))
+	h.replyFaq(ctx, b, msg, sb.String())
+}
+
+// showTemplate присылает текст шаблона блоком кода, чтобы его было удобно скопировать, и превью
+func (h *CommandHandler) showTemplate(ctx context.Context, b *bot.Bot, msg *models.Message, name string) {
+	p := h.printer(msg)
+	source, overridden := h.templates.Source(ctx, msg.Chat.ID, p.Lang, name)
+	key := "template.show.default"
+	if overridden {
+		key = "template.show.overridden"
+	}
+	header := p.T(key, name, p.T("template."+name+".vars"))
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            header + source,
+		Entities: []models.MessageEntity{{
+			Type:   models.MessageEntityTypePre,
+			Offset: telegram.UTF16Len(header),
+			Length: telegram.UTF16Len(source),
+		}},
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID},
+	})
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            h.templates.Preview(ctx, msg.Chat.ID, name, msg.From, p),
+		ParseMode:       models.ParseModeMarkdown,
+		LinkPreviewOptions: &models.LinkPreviewOptions{
+			IsDisabled: bot.True(),
+		},
+	})
+}
+
+// setTemplate сохраняет шаблон, только если он собрался и Telegram принял разметку превью
+func (h *CommandHandler) setTemplate(ctx context.Context, b *bot.Bot, msg *models.Message, name string) {
+	// Берём текст как есть, с переводами строк: после "/morty_template <имя> set"
+	p := h.printer(msg)
+	body := telegram.CommandPayload(msg.Text)
+	body = strings.TrimSpace(body[len(name):])
+	body = strings.TrimSpace(body[len("set"):])
+	if body == "" {
+		h.replyFaq(ctx, b, msg, p.T("template.empty"))
+		return
+	}
+
+	preview, err := h.templates.Check(name, body, msg.From, p)
+	if err != nil {
+		h.logger.Debug(ctx, "handleMortyTemplate: bad template", "template", name, "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, p.T("template.broken", err.Error()))
+		return
+	}
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            preview,
+		ParseMode:       models.ParseModeMarkdown,
+		LinkPreviewOptions: &models.LinkPreviewOptions{
+			IsDisabled: bot.True(),
+		},
+	})
+	if err != nil {
+		h.logger.Debug(ctx, "handleMortyTemplate: preview rejected", "template", name, "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, p.T("template.rejected", err.Error()))
+		return
+	}
+
+	if err := h.templates.TemplateUseCase.Set(ctx, msg.Chat.ID, name, body, msg.From.ID); err != nil {
+		h.logger.Error(ctx, "handleMortyTemplate: save template error", "template", name, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		h.replyFaq(ctx, b, msg, p.T("error.generic"))
+		return
+	}
+	h.templates.Invalidate(msg.Chat.ID)
+	h.logger.Info(ctx, "handleMortyTemplate: template saved", "template", name, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	h.replyFaq(ctx, b, msg, p.T("template.saved", name))
+}
@@ -2,8 +2,8 @@ package commands
 
 import (
 	"context"
-	"fmt"
 	"morty-smith-34-c/internal/delivery/telegram"
+	"morty-smith-34-c/pkg/duration"
 	"strings"
 	"time"
 
@@ -11,18 +11,41 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
+// maxMuteDuration limits how long each role can mute for.
+// Права чата могут разрешить мут и пользователю, ему достаётся потолок модера.
+var maxMuteDuration = map[string]time.Duration{
+	"user":       7 * 24 * time.Hour,
+	"moder":      7 * 24 * time.Hour,
+	"admin":      30 * 24 * time.Hour,
+	"superadmin": duration.Forever,
+}
+
+// muteLimit - потолок мута для роли, неизвестной роли - как модеру
+func muteLimit(role string) time.Duration {
+	if limit, ok := maxMuteDuration[role]; ok {
+		return limit
+	}
+	return maxMuteDuration["moder"]
+}
+
+// telegramMaxRestriction - ограничение дольше 366 дней Telegram считает бессрочным
+const telegramMaxRestriction = 366 * 24 * time.Hour
+
 // handleMute handles the /mute command
 func (h *CommandHandler) handleMute(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
-	if msg.ReplyToMessage == nil || msg.ReplyToMessage.ID == msg.ReplyToMessage.MessageThreadID {
-		h.logger.Debug(ctx, "handleMute: message is not reply", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+	p := h.printer(msg)
+	target, rest, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		h.logger.Debug(ctx, "handleMute: target not found", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		h.replyTargetNotFound(ctx, b, msg, err)
 		return
 	}
 
-	if msg.ReplyToMessage.From.ID == msg.From.ID {
+	if target.ID == msg.From.ID {
 		h.logger.Debug(ctx, "handleMute: muted yourself try", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   "Погоди, что? Ты хотел себя замутить? Ха-ха, Рик, посмотри на это...",
+			Text:   p.T("mute.self"),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
@@ -30,12 +53,17 @@ func (h *CommandHandler) handleMute(ctx context.Context, b *bot.Bot, msg *models
 		return
 	}
 
-	duration, err := parseDuration(strings.Join(args, " "))
+	if !h.canActOn(ctx, b, msg, target) {
+		return
+	}
+
+	// Всё, что после времени, считаем причиной мута
+	muteDuration, reason, err := duration.ParsePrefix(strings.Join(rest, " "))
 	if err != nil {
 		h.logger.Debug(ctx, "handleMute: missing format time", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("Ой\\-ой\\, %s\\, кажется\\, ты что\\-то напутал с форматом времени\\!", telegram.GenerateMention(msg.From)),
+			Text:   p.Markdown("mute.bad_duration", telegram.GenerateMention(msg.From)),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
@@ -44,11 +72,32 @@ func (h *CommandHandler) handleMute(ctx context.Context, b *bot.Bot, msg *models
 		return
 	}
 
-	if duration < 5*time.Minute {
+	if muteDuration < 5*time.Minute {
 		h.logger.Debug(ctx, "handleMute: time less 5 minute", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("Минимальное время мута — 5 минут\\, как будто у нас есть время на меньшее, %s\\!", telegram.GenerateMention(msg.From)),
+			Text:   p.Markdown("mute.too_short", telegram.GenerateMention(msg.From)),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+
+	role, err := h.UserUseCase.GetRole(ctx, msg.Chat.ID, msg.From.ID)
+	if err != nil {
+		h.logger.Error(ctx, "handleMute: get role error", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		return
+	}
+	if maxDuration := muteLimit(role); muteDuration > maxDuration {
+		h.logger.Debug(ctx, "handleMute: time more than role allows", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text: p.Markdown("mute.too_long",
+				telegram.GenerateMention(msg.From),
+				telegram.EscapeMarkdown(p.Duration(maxDuration)),
+			),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
@@ -57,8 +106,20 @@ func (h *CommandHandler) handleMute(ctx context.Context, b *bot.Bot, msg *models
 		return
 	}
 
-	mutedUserID := msg.ReplyToMessage.From.ID
-	until := time.Now().Add(duration).Unix()
+	if muteDuration > telegramMaxRestriction {
+		// Telegram всё равно замьютит навсегда, пусть и в реестре будет так же
+		muteDuration = duration.Forever
+	}
+
+	mutedUserID := target.ID
+	// Для Telegram until_date = 0 означает бессрочно
+	var until int64
+	var untilTime *time.Time
+	if muteDuration != duration.Forever {
+		t := time.Now().Add(muteDuration)
+		until = t.Unix()
+		untilTime = &t
+	}
 	_, err = b.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
 		ChatID: msg.Chat.ID,
 		UserID: mutedUserID,
@@ -80,13 +141,13 @@ func (h *CommandHandler) handleMute(ctx context.Context, b *bot.Bot, msg *models
 		h.logger.Debug(ctx, "handleMute: cant mute",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 			"err", err,
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("Ох\\, замутить %s не удалось\\, давай попробуем снова\\, %s\\!", telegram.GenerateMention(msg.ReplyToMessage.From), telegram.GenerateMention(msg.From)),
+			Text:   p.Markdown("mute.failed", telegram.GenerateMention(target), telegram.GenerateMention(msg.From)),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
@@ -95,15 +156,32 @@ func (h *CommandHandler) handleMute(ctx context.Context, b *bot.Bot, msg *models
 		return
 	}
 
+	if err := h.MuteUseCase.Mute(ctx, msg.Chat.ID, mutedUserID, msg.From.ID, reason, untilTime); err != nil {
+		h.logger.Error(ctx, "handleMute: save mute error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+	}
+
 	h.logger.Info(ctx, "handleMute: mute",
 		"text", msg.Text,
 		"user", telegram.UserForLogger(msg.From),
-		"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+		"for", telegram.UserForLogger(target),
 		"chat", telegram.ChatForLogger(msg.Chat),
 	)
+	text := h.templates.Render(ctx, msg.Chat.ID, p.Lang, "mute", telegram.TemplateVars{
+		"Target":   telegram.Mention(target),
+		"Admin":    telegram.Mention(msg.From),
+		"Duration": p.Duration(muteDuration),
+		"Forever":  muteDuration == duration.Forever,
+		"Reason":   reason,
+	})
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: msg.Chat.ID,
-		Text:   fmt.Sprintf("Бум\\! %s теперь в муте на %s\\, %s\\!", telegram.GenerateMention(msg.ReplyToMessage.From), strings.Join(args[1:], " "), telegram.GenerateMention(msg.From)),
+		Text:   text,
 		ReplyParameters: &models.ReplyParameters{
 			MessageID: msg.ID,
 		},
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleMuted handles the /muted command: lists active mutes of the chat
+func (h *CommandHandler) handleMuted(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	mutes, err := h.MuteUseCase.GetActive(ctx, msg.Chat.ID)
+	if err != nil {
+		h.logger.Error(ctx, "handleMuted: get mutes error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("error.oops"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+		return
+	}
+
+	h.logger.Debug(ctx, "handleMuted: send mutes",
+		"count", len(mutes),
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	if len(mutes) == 0 {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("muted.empty"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.Markdown("muted.title"))
+	for i, mute := range mutes {
+		until := p.T("muted.forever")
+		if mute.Until != nil {
+			until = p.T("muted.until", mute.Until.In(usecase.MoscowLocation).Format("02.01 15:04"))
+		}
+		sb.WriteString(p.Markdown("muted.item",
+			i+1,
+			h.mentionByTelegramID(ctx, mute.TelegramID),
+			telegram.EscapeMarkdown(until),
+			h.mentionByTelegramID(ctx, mute.ModeratorID),
+		))
+		if mute.Reason != nil {
+			sb.WriteString(p.Markdown("muted.reason", telegram.EscapeMarkdown(*mute.Reason)))
+		}
+		sb.WriteString("\n")
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   sb.String(),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
+
+// mentionByTelegramID builds a mention using the school nick when we know it
+func (h *CommandHandler) mentionByTelegramID(ctx context.Context, telegramID int64) string {
+	user, err := h.UserUseCase.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return telegram.GenerateMention(&models.User{ID: telegramID, FirstName: fmt.Sprintf("ID %d", telegramID)})
+	}
+	return telegram.GenerateMention(&models.User{ID: telegramID, FirstName: displayName(user)})
+}
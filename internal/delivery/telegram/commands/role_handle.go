@@ -2,119 +2,121 @@ package commands
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"morty-smith-34-c/internal/app/usecase"
 	"morty-smith-34-c/internal/delivery/telegram"
-	"strings"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
-func (h *CommandHandler) RoleHandle(ctx context.Context, b *bot.Bot, msg *models.Message) {
-	args := strings.Fields(msg.Text)
-	if len(args) == 0 {
-		h.logger.Debug(ctx, "RoleHandle: no args",
-			"text", msg.Text,
-			"user", telegram.UserForLogger(msg.From),
-			"chat", telegram.ChatForLogger(msg.Chat),
-		)
-		return
-	}
-	if msg.ReplyToMessage == nil || msg.ReplyToMessage.ID == msg.ReplyToMessage.MessageThreadID || args[0] != "/role" {
-		h.logger.Debug(ctx, "RoleHandle: message dont reply",
+// handleRole handles the /role command: changes the role of a user in the chat
+func (h *CommandHandler) handleRole(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, rest, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		h.logger.Debug(ctx, "handleRole: target not found",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
+		h.replyTargetNotFound(ctx, b, msg, err)
 		return
 	}
-	err := h.UserUseCase.CheckRole(ctx, msg.From.ID, []string{"superadmin"})
-	if err != nil {
-		h.logger.Debug(ctx, "RoleHandle: user not superadmin",
+	if len(rest) < 1 {
+		h.logger.Debug(ctx, "handleRole: missing args",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.T("role.missing"),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
 		return
 	}
-	if len(args) < 2 {
-		h.logger.Debug(ctx, "RoleHandle: missing args",
+	if !usecase.IsValidRole(rest[0]) {
+		h.logger.Debug(ctx, "handleRole: missing role",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   "О-о-ох, нет! Укажи роль пожалуйста, на меня и так Рик уже ругается...",
+			Text:   p.T("role.unknown"),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
 		})
 		return
 	}
-	roles := []string{
-		"user",
-		"moder",
-		"admin",
-		"superadmin",
-	}
-	validate := false
-	for _, r := range roles {
-		if r == args[1] {
-			validate = true
-			break
-		}
+	exists, err := h.UserUseCase.Exists(ctx, target.ID)
+	if err != nil {
+		return
 	}
-	if !validate {
-		h.logger.Debug(ctx, "RoleHandle: missing role",
+	if !exists {
+		h.logger.Info(ctx, "handleRole: not found user",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   "О-о-ох, нет! Я не понимаю о чем ты... Попробуй иначе [user, moder, admin, superadmin]",
+			Text:   p.Markdown("role.user_unknown", telegram.GenerateMention(msg.From)),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
+			ParseMode: models.ParseModeMarkdown,
 		})
 		return
 	}
-	exists, err := h.UserUseCase.Exists(ctx, msg.ReplyToMessage.From.ID)
-	if err != nil {
-		return
-	}
-	if !exists {
-		h.logger.Info(ctx, "RoleHandle: not found user",
+	if err := h.UserUseCase.ChangeRole(ctx, msg.Chat.ID, msg.From.ID, target.ID, rest[0]); err != nil {
+		h.logger.Info(ctx, "handleRole: role change rejected",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("О\\-ох\\, %s\\, кажется\\, я не знаю кто это\\!", telegram.GenerateMention(msg.From)),
+			Text:   roleChangeErrorText(p, err),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
-			ParseMode: models.ParseModeMarkdown,
 		})
 		return
 	}
-	h.logger.Info(ctx, "RoleHandle: set role",
+	h.logger.Info(ctx, "handleRole: set role",
 		"text", msg.Text,
 		"user", telegram.UserForLogger(msg.From),
-		"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+		"for", telegram.UserForLogger(target),
 		"chat", telegram.ChatForLogger(msg.Chat),
 	)
-	h.UserUseCase.UpdateRole(ctx, msg.ReplyToMessage.From.ID, args[1])
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: msg.Chat.ID,
-		Text:   "В-в-всё получилось! Наконец-то я могу отдохнуть...",
+		Text:   p.T("role.done"),
 		ReplyParameters: &models.ReplyParameters{
 			MessageID: msg.ID,
 		},
 	})
 }
+
+// roleChangeErrorText explains why the role was not changed
+func roleChangeErrorText(p telegram.Printer, err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrNotLowerRole):
+		return p.T("role.error.not_lower")
+	case errors.Is(err, usecase.ErrRoleAboveActor):
+		return p.T("role.error.above_actor")
+	case errors.Is(err, usecase.ErrLastSuperadmin):
+		return p.T("role.error.last_superadmin")
+	}
+	return p.T("error.oops")
+}
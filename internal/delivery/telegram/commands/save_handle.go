@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"morty-smith-34-c/internal/app/entity"
 	"morty-smith-34-c/internal/delivery/telegram"
 	"strings"
 
@@ -10,73 +11,60 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
-func (h *CommandHandler) SaveHandle(ctx context.Context, b *bot.Bot, msg *models.Message) {
-	args := strings.Fields(msg.Text)
-	if len(args) == 0 || args[0] != "/save" {
-		return
-	}
-	if msg.ReplyToMessage == nil || msg.ReplyToMessage.ID == msg.ReplyToMessage.MessageThreadID {
-		h.logger.Debug(ctx, "SaveHandle: message is not reply",
-			"text", msg.Text,
-			"user", telegram.UserForLogger(msg.From),
-			"chat", telegram.ChatForLogger(msg.Chat),
-		)
-		return
-	}
-	err := h.UserUseCase.CheckRole(ctx, msg.From.ID, []string{"admin", "superadmin"})
+// handleSave handles the /save command: remembers the school nick of a user
+func (h *CommandHandler) handleSave(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, rest, err := h.resolveTarget(ctx, msg, args[1:])
 	if err != nil {
-		h.logger.Debug(ctx, "SaveHandle: user is not admin or superadmin",
+		h.logger.Debug(ctx, "handleSave: target not found",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
+		h.replyTargetNotFound(ctx, b, msg, err)
 		return
 	}
-	var schoolNick string
-	if len(args) > 1 {
-		schoolNick = strings.TrimSpace(strings.TrimPrefix(msg.Text, "/save"))
-	} else {
-		schoolNick = fmt.Sprintf("%d", msg.ReplyToMessage.From.ID)
-	}
-	exists, err := h.UserUseCase.Exists(ctx, msg.ReplyToMessage.From.ID)
+	// Без ника - одобряем вручную, школьного ника у пользователя не будет
+	schoolNick := strings.Join(rest, " ")
+	exists, err := h.UserUseCase.Exists(ctx, target.ID)
 	if err != nil {
-		h.logger.Error(ctx, "SaveHandle: check user exists",
+		h.logger.Error(ctx, "handleSave: check user exists",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 			"err", err,
 		)
 		return
 	}
 	if exists {
-		if len(args) > 1 {
-			rename, err := h.UserUseCase.UpdateSchoolNick(ctx, msg.ReplyToMessage.From.ID, schoolNick)
+		if len(rest) > 0 {
+			if !h.canActOn(ctx, b, msg, target) {
+				return
+			}
+			rename, err := h.UserUseCase.UpdateSchoolNick(ctx, target.ID, schoolNick, entity.NickSourceManual, &msg.From.ID)
 			if err != nil {
-				h.logger.Error(ctx, "SaveHandle: update nick error",
+				h.logger.Error(ctx, "handleSave: update nick error",
 					"text", msg.Text,
 					"user", telegram.UserForLogger(msg.From),
-					"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+					"for", telegram.UserForLogger(target),
 					"chat", telegram.ChatForLogger(msg.Chat),
 					"err", err,
 				)
 				return
 			}
 			if rename {
-				h.logger.Debug(ctx, "SaveHandle: rename user",
+				h.logger.Debug(ctx, "handleSave: rename user",
 					"text", msg.Text,
 					"user", telegram.UserForLogger(msg.From),
-					"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+					"for", telegram.UserForLogger(target),
 					"chat", telegram.ChatForLogger(msg.Chat),
 				)
 				b.SendMessage(ctx, &bot.SendMessageParams{
 					ChatID: msg.Chat.ID,
-					Text: fmt.Sprintf(
-						"О\\-ох\\, %s\\, кажется\\, я уже знаю его\\, но не под этим именем\\, теперь я запомнил [%s](tg://user?id=%d)\\!",
+					Text: p.Markdown("save.renamed",
 						telegram.GenerateMention(msg.From),
-						schoolNick,
-						msg.ReplyToMessage.ID,
+						fmt.Sprintf("[%s](tg://user?id=%d)", telegram.EscapeMarkdown(schoolNick), target.ID),
 					),
 					ReplyParameters: &models.ReplyParameters{
 						MessageID: msg.ID,
@@ -86,15 +74,15 @@ func (h *CommandHandler) SaveHandle(ctx context.Context, b *bot.Bot, msg *models
 				return
 			}
 		}
-		h.logger.Debug(ctx, "SaveHandle: user exists with this nick",
+		h.logger.Debug(ctx, "handleSave: user exists with this nick",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("О\\-ох\\, %s\\, кажется\\, я уже знаю его\\!", telegram.GenerateMention(msg.From)),
+			Text:   p.Markdown("save.exists", telegram.GenerateMention(msg.From)),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
@@ -102,36 +90,45 @@ func (h *CommandHandler) SaveHandle(ctx context.Context, b *bot.Bot, msg *models
 		})
 		return
 	}
-	err = h.UserUseCase.SaveNickname(ctx, msg.ReplyToMessage.From.ID, schoolNick)
+	err = h.UserUseCase.SaveNickname(ctx, target.ID, schoolNick, entity.NickSourceManual, &msg.From.ID)
 	if err != nil {
-		h.logger.Error(ctx, "SaveHandle: dont save user",
+		h.logger.Error(ctx, "handleSave: dont save user",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 			"err", err,
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   "О-о-о, о нет! Кажется что-то пошло не так...!",
+			Text:   p.T("error.oops"),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
 		})
 		return
 	}
-	h.userHandler.RemoveUserFromTimers(ctx, b, msg.Chat.ID, msg.ReplyToMessage.From.ID)
-	h.logger.Info(ctx, "SaveHandle: save user",
+	h.userHandler.RemoveUserFromTimers(ctx, b, msg.Chat.ID, target.ID)
+	if err := h.ChatMemberUseCase.Verified(ctx, msg.Chat.ID, target.ID); err != nil {
+		h.logger.Error(ctx, "handleSave: track member error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+	}
+	h.logger.Info(ctx, "handleSave: save user",
 		"text", msg.Text,
 		"user", telegram.UserForLogger(msg.From),
-		"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+		"for", telegram.UserForLogger(target),
 		"chat", telegram.ChatForLogger(msg.Chat),
 	)
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: msg.Chat.ID,
-		Text: fmt.Sprintf("Э\\, %s\\, всё получилось\\! Я записал %s\\, теперь это наш человек\\!",
+		Text: p.Markdown("save.done",
 			telegram.GenerateMention(msg.From),
-			telegram.GenerateMention(msg.ReplyToMessage.From),
+			telegram.GenerateMention(target),
 		),
 		ReplyParameters: &models.ReplyParameters{
 			MessageID: msg.ID,
@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+var errTargetNotFound = errors.New("target not found")
+
+// unknownTargetError - цель указали, но такого пользователя Морти не знает
+type unknownTargetError struct {
+	arg string
+}
+
+func (e *unknownTargetError) Error() string {
+	return fmt.Sprintf("unknown target %q", e.arg)
+}
+
+// resolveTarget finds who the moderation command is about and returns the remaining arguments.
+// Sources in order: reply, text mention, @username, numeric Telegram ID, school nick.
+// @username is looked up in memory, then in the users table; a school nick also matches
+// former nicks from the nick history. args must not include the command itself.
+func (h *CommandHandler) resolveTarget(ctx context.Context, msg *models.Message, args []string) (*models.User, []string, error) {
+	if msg.ReplyToMessage != nil && msg.ReplyToMessage.ID != msg.ReplyToMessage.MessageThreadID && msg.ReplyToMessage.From != nil {
+		return msg.ReplyToMessage.From, args, nil
+	}
+
+	// Упоминание пользователя без username: имя может быть из нескольких слов
+	if user, rest, ok := targetFromTextMention(msg); ok {
+		return user, rest, nil
+	}
+
+	if len(args) == 0 {
+		return nil, nil, errTargetNotFound
+	}
+	arg, rest := args[0], args[1:]
+
+	if strings.HasPrefix(arg, "@") {
+		if user := h.userByUsername(ctx, arg); user != nil {
+			return user, rest, nil
+		}
+		// Школьный ник тоже часто пишут через @
+	} else if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		user := &models.User{ID: id, FirstName: fmt.Sprintf("ID %d", id)}
+		if known, err := h.UserUseCase.GetByTelegramID(ctx, id); err == nil {
+			user.FirstName = displayName(known)
+		}
+		return user, rest, nil
+	}
+
+	known, err := h.UserUseCase.FindBySchoolName(ctx, strings.TrimPrefix(arg, "@"))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Error(ctx, "resolveTarget: find by school name error", "arg", arg, "chat", telegram.ChatForLogger(msg.Chat), "err", err)
+		}
+		return nil, nil, &unknownTargetError{arg: arg}
+	}
+	return &models.User{ID: known.TelegramID, FirstName: displayName(known)}, rest, nil
+}
+
+// userByUsername ищет @username среди тех, кого бот видел с запуска, а потом в базе
+func (h *CommandHandler) userByUsername(ctx context.Context, arg string) *models.User {
+	username := strings.TrimPrefix(arg, "@")
+	if id, ok := h.userCache.GetIDByUsername(username); ok {
+		return &models.User{ID: id, Username: username, FirstName: arg}
+	}
+	known, err := h.UserUseCase.GetByUsername(ctx, username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Error(ctx, "userByUsername: get by username error", "username", username, "err", err)
+		}
+		return nil
+	}
+	user := &models.User{ID: known.TelegramID, Username: username, FirstName: arg}
+	h.userCache.Remember(user)
+	return user
+}
+
+// displayName returns the school nick, or the Telegram ID for users approved without one
+func displayName(user *entity.User) string {
+	if user.SchoolName == nil {
+		return fmt.Sprintf("ID %d", user.TelegramID)
+	}
+	return *user.SchoolName
+}
+
+// targetFromTextMention takes the user from a text_mention entity right after the command.
+func targetFromTextMention(msg *models.Message) (*models.User, []string, bool) {
+	text := utf16.Encode([]rune(msg.Text))
+	for _, entity := range msg.Entities {
+		if entity.Type != models.MessageEntityTypeTextMention || entity.User == nil {
+			continue
+		}
+		if entity.Offset+entity.Length > len(text) {
+			continue
+		}
+		before := strings.Fields(string(utf16.Decode(text[:entity.Offset])))
+		if len(before) != 1 {
+			// Упоминание не первым аргументом - это не цель команды
+			continue
+		}
+		after := strings.Fields(string(utf16.Decode(text[entity.Offset+entity.Length:])))
+		return entity.User, after, true
+	}
+	return nil, nil, false
+}
+
+// replyTargetNotFound tells the moderator how to point at someone,
+// naming the user that couldn't be found if one was given
+func (h *CommandHandler) replyTargetNotFound(ctx context.Context, b *bot.Bot, msg *models.Message, err error) {
+	p := h.printer(msg)
+	text := p.Markdown("target.not_found", telegram.GenerateMention(msg.From))
+	var unknown *unknownTargetError
+	if errors.As(err, &unknown) {
+		text = p.Markdown("target.unknown", telegram.GenerateMention(msg.From), telegram.EscapeMarkdown(unknown.arg))
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   text,
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
+
+// canActOn checks the role hierarchy and explains the refusal to the moderator
+func (h *CommandHandler) canActOn(ctx context.Context, b *bot.Bot, msg *models.Message, target *models.User) bool {
+	err := h.UserUseCase.CanActOn(ctx, msg.Chat.ID, msg.From.ID, target.ID)
+	if err == nil {
+		return true
+	}
+	h.logger.Debug(ctx, "canActOn: action rejected",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"for", telegram.UserForLogger(target),
+		"chat", telegram.ChatForLogger(msg.Chat),
+		"err", err,
+	)
+	p := h.printer(msg)
+	text := p.T("error.oops")
+	if errors.Is(err, usecase.ErrNotLowerRole) {
+		text = p.T("target.not_lower")
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   text,
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+	})
+	return false
+}
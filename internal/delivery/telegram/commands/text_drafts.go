@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// textDraft - правила или FAQ, которые ждут подтверждения автора
+type textDraft struct {
+	ChatID    int64
+	ThreadID  int
+	Kind      string
+	AuthorID  int64
+	Text      telegram.RichText
+	CreatedAt time.Time
+}
+
+// textDrafts хранит черновики до нажатия кнопки. После перезапуска они теряются,
+// тогда команду придётся повторить.
+type textDrafts struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	lastID int64
+	items  map[string]*textDraft
+}
+
+func newTextDrafts(ttl time.Duration) *textDrafts {
+	return &textDrafts{
+		ttl:   ttl,
+		items: make(map[string]*textDraft),
+	}
+}
+
+// Add сохраняет черновик и возвращает его ID для кнопок
+func (d *textDrafts) Add(draft *textDraft) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Заодно выкидываем черновики, про которые забыли
+	for id, item := range d.items {
+		if time.Since(item.CreatedAt) > d.ttl {
+			delete(d.items, id)
+		}
+	}
+
+	d.lastID++
+	id := strconv.FormatInt(d.lastID, 36)
+	draft.CreatedAt = time.Now()
+	d.items[id] = draft
+	return id
+}
+
+// Get возвращает черновик, если он ещё не протух
+func (d *textDrafts) Get(id string) (*textDraft, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	draft, ok := d.items[id]
+	if !ok || time.Since(draft.CreatedAt) > d.ttl {
+		delete(d.items, id)
+		return nil, false
+	}
+	return draft, true
+}
+
+func (d *textDrafts) Delete(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.items, id)
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"morty-smith-34-c/internal/delivery/telegram"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleUnban handles the /unban command
+func (h *CommandHandler) handleUnban(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, _, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		h.logger.Debug(ctx, "handleUnban: target not found",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"chat", telegram.ChatForLogger(msg.Chat),
+		)
+		h.replyTargetNotFound(ctx, b, msg, err)
+		return
+	}
+
+	if target.ID == msg.From.ID {
+		h.logger.Debug(ctx, "handleUnban: unbanned yourself try", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
+		return
+	}
+
+	if !h.canActOn(ctx, b, msg, target) {
+		return
+	}
+
+	// OnlyIfBanned: без него Telegram выкинет из чата того, кто в нём состоит
+	_, err = b.UnbanChatMember(ctx, &bot.UnbanChatMemberParams{
+		ChatID:       msg.Chat.ID,
+		UserID:       target.ID,
+		OnlyIfBanned: true,
+	})
+	if err != nil {
+		h.logger.Debug(ctx, "handleUnban: cant unban",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   p.Markdown("unban.failed", telegram.GenerateMention(target), telegram.GenerateMention(msg.From)),
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+
+	h.logger.Info(ctx, "handleUnban: unban",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"for", telegram.UserForLogger(target),
+		"chat", telegram.ChatForLogger(msg.Chat),
+	)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   p.Markdown("unban.done", telegram.GenerateMention(target), telegram.GenerateMention(msg.From)),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
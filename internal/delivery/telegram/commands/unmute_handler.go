@@ -2,7 +2,6 @@ package commands
 
 import (
 	"context"
-	"fmt"
 	"morty-smith-34-c/internal/delivery/telegram"
 	"time"
 
@@ -11,23 +10,30 @@ import (
 )
 
 // handleUnmute handles the /unmute command
-func (h *CommandHandler) handleUnmute(ctx context.Context, b *bot.Bot, msg *models.Message) {
-	if msg.ReplyToMessage == nil || msg.ReplyToMessage.ID == msg.ReplyToMessage.MessageThreadID {
-		h.logger.Debug(ctx, "handleUnmute: message is not reply",
+func (h *CommandHandler) handleUnmute(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, _, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		h.logger.Debug(ctx, "handleUnmute: target not found",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
 			"chat", telegram.ChatForLogger(msg.Chat),
 		)
+		h.replyTargetNotFound(ctx, b, msg, err)
 		return
 	}
 
-	if msg.ReplyToMessage.From.ID == msg.From.ID {
+	if target.ID == msg.From.ID {
 		h.logger.Debug(ctx, "handleMute: unmuted yourself try", "text", msg.Text, "user", telegram.UserForLogger(msg.From), "chat", telegram.ChatForLogger(msg.Chat))
 		return
 	}
 
-	unmutedUserID := msg.ReplyToMessage.From.ID
-	_, err := b.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+	if !h.canActOn(ctx, b, msg, target) {
+		return
+	}
+
+	unmutedUserID := target.ID
+	_, err = b.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
 		ChatID: msg.Chat.ID,
 		UserID: unmutedUserID,
 		Permissions: &models.ChatPermissions{
@@ -48,13 +54,13 @@ func (h *CommandHandler) handleUnmute(ctx context.Context, b *bot.Bot, msg *mode
 		h.logger.Debug(ctx, "handleUnmute: cant unmute",
 			"text", msg.Text,
 			"user", telegram.UserForLogger(msg.From),
-			"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+			"for", telegram.UserForLogger(target),
 			"chat", telegram.ChatForLogger(msg.Chat),
 			"err", err,
 		)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: msg.Chat.ID,
-			Text:   fmt.Sprintf("О нет\\! Не могу размутить %s\\, %s\\, помоги мне\\!", telegram.GenerateMention(msg.ReplyToMessage.From), telegram.GenerateMention(msg.From)),
+			Text:   p.Markdown("unmute.failed", telegram.GenerateMention(target), telegram.GenerateMention(msg.From)),
 			ReplyParameters: &models.ReplyParameters{
 				MessageID: msg.ID,
 			},
@@ -63,15 +69,25 @@ func (h *CommandHandler) handleUnmute(ctx context.Context, b *bot.Bot, msg *mode
 		return
 	}
 
+	if _, err := h.MuteUseCase.Unmute(ctx, msg.Chat.ID, unmutedUserID); err != nil {
+		h.logger.Error(ctx, "handleUnmute: remove mute error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+	}
+
 	h.logger.Info(ctx, "handleUnmute: unmute",
 		"text", msg.Text,
 		"user", telegram.UserForLogger(msg.From),
-		"for", telegram.UserForLogger(msg.ReplyToMessage.From),
+		"for", telegram.UserForLogger(target),
 		"chat", telegram.ChatForLogger(msg.Chat),
 	)
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: msg.Chat.ID,
-		Text:   fmt.Sprintf("Фух\\! %s размучен\\, %s\\!", telegram.GenerateMention(msg.ReplyToMessage.From), telegram.GenerateMention(msg.From)),
+		Text:   h.templates.Render(ctx, msg.Chat.ID, p.Lang, "unmute", telegram.TemplateVars{"Target": telegram.Mention(target), "Admin": telegram.Mention(msg.From)}),
 		ReplyParameters: &models.ReplyParameters{
 			MessageID: msg.ID,
 		},
@@ -1,60 +1,58 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
-)
 
-// parseDuration parses human-readable time format into time.Duration
-func parseDuration(input string) (time.Duration, error) {
-	re := regexp.MustCompile(`(?i)(\d+\s*нед|\d+\s*день|\d+\s*дня|\d+\s*дней|\d+\s*час|\d+\s*мин|\d+\s*сек)`)
-	matches := re.FindAllString(strings.ToLower(input), -1)
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
 
-	if len(matches) == 0 {
-		return 0, fmt.Errorf("неверный формат времени")
+// parseTimeRange parses "ЧЧ:ММ-ЧЧ:ММ" into minutes since midnight
+func parseTimeRange(input string) (int, int, error) {
+	parts := strings.Split(input, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("неверный формат интервала")
 	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("пустой интервал")
+	}
+	return start, end, nil
+}
 
-	var duration time.Duration
-	for _, match := range matches {
-		var value int
-		var err error
-
-		if strings.Contains(match, "день") {
-			value, err = extractNumber(match, "день")
-			duration += time.Duration(value) * 24 * time.Hour
-		} else if strings.Contains(match, "дня") {
-			value, err = extractNumber(match, "дня")
-			duration += time.Duration(value) * time.Hour
-		} else if strings.Contains(match, "дней") {
-			value, err = extractNumber(match, "дней")
-			duration += time.Duration(value) * time.Hour
-		} else if strings.Contains(match, "час") {
-			value, err = extractNumber(match, "час")
-			duration += time.Duration(value) * time.Hour
-		} else if strings.Contains(match, "мин") {
-			value, err = extractNumber(match, "мин")
-			duration += time.Duration(value) * time.Minute
-		} else if strings.Contains(match, "сек") {
-			value, err = extractNumber(match, "сек")
-			duration += time.Duration(value) * time.Second
-		} else if strings.Contains(match, "нед") {
-			value, err = extractNumber(match, "нед")
-			duration += time.Duration(value) * 24 * 7 * time.Hour
-		}
-
-		if err != nil {
-			return 0, err
-		}
+// parseClock parses "ЧЧ:ММ" into minutes since midnight
+func parseClock(input string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(input))
+	if err != nil {
+		return 0, fmt.Errorf("неверный формат времени: %w", err)
 	}
+	return t.Hour()*60 + t.Minute(), nil
+}
 
-	return duration, nil
+// formatClock formats minutes since midnight as "ЧЧ:ММ"
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
 }
 
-// extractNumber extracts the number from the time string
-func extractNumber(input, unit string) (int, error) {
-	value := strings.TrimSpace(strings.Replace(input, unit, "", 1))
-	return strconv.Atoi(value)
+// deleteAfter removes the bot message after the given delay
+func deleteAfter(ctx context.Context, b *bot.Bot, msg *models.Message, delay time.Duration) {
+	if msg == nil {
+		return
+	}
+	time.AfterFunc(delay, func() {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+		})
+	})
 }
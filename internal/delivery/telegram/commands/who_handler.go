@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// handleWho handles the /who command: finds the Telegram account linked to a school login
+func (h *CommandHandler) handleWho(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	login := args[1]
+	user, err := h.UserUseCase.GetBySchoolName(ctx, login)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Error(ctx, "handleWho: get user error",
+				"text", msg.Text,
+				"user", telegram.UserForLogger(msg.From),
+				"chat", telegram.ChatForLogger(msg.Chat),
+				"err", err,
+			)
+		}
+		h.replyProfile(ctx, b, msg, p.Markdown("who.not_found", telegram.EscapeMarkdown(login)))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.Markdown("who.found",
+		telegram.EscapeMarkdown(user.Nick()),
+		telegram.GenerateMention(&models.User{ID: user.TelegramID, FirstName: user.Nick()}),
+		fmt.Sprintf("`%d`", user.TelegramID),
+	))
+	chats := h.memberChats(ctx, b, p, user.TelegramID)
+	if len(chats) == 0 {
+		sb.WriteString("\n" + p.Markdown("who.no_chats"))
+	} else {
+		sb.WriteString("\n" + p.Markdown("who.chats"))
+		for _, chat := range chats {
+			sb.WriteString("• " + telegram.EscapeMarkdown(chat) + "\n")
+		}
+	}
+
+	h.logger.Debug(ctx, "handleWho: send who",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+		"for", user.TelegramID,
+	)
+	h.replyProfile(ctx, b, msg, sb.String())
+}
+
+// memberChats возвращает названия чатов, где сейчас состоит пользователь
+func (h *CommandHandler) memberChats(ctx context.Context, b *bot.Bot, p telegram.Printer, telegramID int64) []string {
+	memberships, err := h.ChatMemberUseCase.ActiveChats(ctx, telegramID)
+	if err != nil {
+		h.logger.Error(ctx, "memberChats: get chats error", "err", err)
+		return nil
+	}
+	var names []string
+	for _, membership := range memberships {
+		chat, err := h.ChatUseCase.GetByChatID(ctx, membership.ChatID)
+		if err != nil {
+			continue
+		}
+		name := chat.CampusName
+		if info, err := b.GetChat(ctx, &bot.GetChatParams{ChatID: chat.ChatID}); err == nil && info.Title != "" {
+			name = fmt.Sprintf("%s (%s)", info.Title, chat.CampusName)
+		}
+		names = append(names, p.T("who.chat_since", name, membership.JoinedAt.In(usecase.MoscowLocation).Format("02.01.2006")))
+	}
+	return names
+}
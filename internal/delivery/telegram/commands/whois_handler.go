@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/delivery/telegram"
+	"morty-smith-34-c/internal/school"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// handleWhois handles the /whois command: who is this member at school
+func (h *CommandHandler) handleWhois(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	p := h.printer(msg)
+	target, _, err := h.resolveTarget(ctx, msg, args[1:])
+	if err != nil {
+		if len(args) < 2 || strings.HasPrefix(args[1], "@") {
+			h.replyTargetNotFound(ctx, b, msg, err)
+			return
+		}
+		// В базе такого ника нет, но школа может его знать
+		h.sendProfile(ctx, b, msg, nil, nil, args[1])
+		return
+	}
+
+	user, err := h.UserUseCase.GetByTelegramID(ctx, target.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.Error(ctx, "handleWhois: get user error",
+			"text", msg.Text,
+			"user", telegram.UserForLogger(msg.From),
+			"for", telegram.UserForLogger(target),
+			"chat", telegram.ChatForLogger(msg.Chat),
+			"err", err,
+		)
+		h.replyProfile(ctx, b, msg, p.Markdown("error.oops"))
+		return
+	}
+	login := ""
+	if user != nil {
+		login = user.Nick()
+	}
+	h.sendProfile(ctx, b, msg, target, user, login)
+}
+
+// handleMe handles the /me command: the caller's own profile
+func (h *CommandHandler) handleMe(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+	user, err := h.UserUseCase.GetByTelegramID(ctx, msg.From.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Error(ctx, "handleMe: get user error",
+				"text", msg.Text,
+				"user", telegram.UserForLogger(msg.From),
+				"chat", telegram.ChatForLogger(msg.Chat),
+				"err", err,
+			)
+		}
+		h.replyProfile(ctx, b, msg, h.printer(msg).Markdown("whois.me_unknown", telegram.GenerateMention(msg.From)))
+		return
+	}
+	h.sendProfile(ctx, b, msg, msg.From, user, user.Nick())
+}
+
+// sendProfile собирает карточку из того, что знаем мы, и того, что знает школа.
+// target и user могут быть nil, если человека нет в Telegram или в базе.
+func (h *CommandHandler) sendProfile(ctx context.Context, b *bot.Bot, msg *models.Message, target *models.User, user *entity.User, login string) {
+	p := h.printer(msg)
+	var sb strings.Builder
+	if target != nil {
+		sb.WriteString(fmt.Sprintf("*Telegram*\\: %s \\(ID `%d`\\)\n", telegram.GenerateMention(target), target.ID))
+		role, err := h.UserUseCase.GetRole(ctx, msg.Chat.ID, target.ID)
+		if err == nil {
+			sb.WriteString(profileField(p, "whois.role", telegram.EscapeMarkdown(role)))
+		}
+	}
+	if user != nil {
+		if user.SchoolName != nil {
+			sb.WriteString(profileField(p, "whois.nick", telegram.EscapeMarkdown(*user.SchoolName)))
+		} else {
+			sb.WriteString(profileField(p, "whois.nick", p.Markdown("whois.nick_manual")))
+		}
+		sb.WriteString(profileField(p, "whois.known_since", telegram.EscapeMarkdown(user.CreatedAt.Format("02.01.2006"))))
+	} else if target != nil {
+		sb.WriteString(p.Markdown("whois.not_saved") + "\n")
+	}
+
+	if login != "" {
+		profile, err := h.getProfile(ctx, login)
+		switch {
+		case err == nil:
+			sb.WriteString(formatProfile(p, profile))
+		case errors.Is(err, school.ErrNotFound):
+			sb.WriteString("\n" + p.Markdown("whois.school_unknown", telegram.EscapeMarkdown(login)))
+		default:
+			h.logger.Error(ctx, "sendProfile: school api error",
+				"text", msg.Text,
+				"user", telegram.UserForLogger(msg.From),
+				"chat", telegram.ChatForLogger(msg.Chat),
+				"login", login,
+				"err", err,
+			)
+			sb.WriteString("\n" + p.Markdown("whois.school_down"))
+		}
+	}
+
+	h.logger.Debug(ctx, "sendProfile: send profile",
+		"text", msg.Text,
+		"user", telegram.UserForLogger(msg.From),
+		"chat", telegram.ChatForLogger(msg.Chat),
+		"login", login,
+	)
+	h.replyProfile(ctx, b, msg, sb.String())
+}
+
+// getProfile берёт профиль из кеша или из School API
+func (h *CommandHandler) getProfile(ctx context.Context, login string) (*school.Participant, error) {
+	if profile, ok := h.profileCache.Get(login); ok {
+		return profile, nil
+	}
+	profile, err := h.schoolClient.Participant(school.WithPriority(ctx, school.PriorityInteractive), login)
+	if err != nil {
+		return nil, err
+	}
+	h.profileCache.Set(login, profile)
+	return profile, nil
+}
+
+func formatProfile(p telegram.Printer, profile *school.Participant) string {
+	var sb strings.Builder
+	sb.WriteString("\n*" + p.Markdown("whois.school") + "*\n")
+	sb.WriteString(p.Markdown("whois.login", telegram.EscapeMarkdown(profile.Login)) + "\n")
+	sb.WriteString(p.Markdown("whois.level", profile.Level, profile.ExpValue, profile.ExpToNextLevel) + "\n")
+	sb.WriteString(p.Markdown("whois.class", telegram.EscapeMarkdown(profile.ClassName)) + "\n")
+	sb.WriteString(p.Markdown("whois.parallel", telegram.EscapeMarkdown(profile.ParallelName)) + "\n")
+	sb.WriteString(p.Markdown("whois.campus", telegram.EscapeMarkdown(profile.Campus.ShortName)) + "\n")
+	sb.WriteString(p.Markdown("whois.status", telegram.EscapeMarkdown(profile.Status)))
+	return sb.String()
+}
+
+// profileField - строка карточки с жирной подписью, value уже в MarkdownV2
+func profileField(p telegram.Printer, key, value string) string {
+	return "*" + p.Markdown(key) + "*\\: " + value + "\n"
+}
+
+func (h *CommandHandler) replyProfile(ctx context.Context, b *bot.Bot, msg *models.Message, text string) {
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: msg.MessageThreadID,
+		Text:            text,
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
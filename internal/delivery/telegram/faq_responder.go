@@ -0,0 +1,267 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/storage/cache"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// AutoFaqCallbackPrefix - кнопка под автоответом: autofaq:wrong:<id вопроса>
+const AutoFaqCallbackPrefix = "autofaq:"
+
+const (
+	// autoFaqEntryCooldown - один и тот же ответ в чате не чаще, чем раз в это время
+	autoFaqEntryCooldown = 10 * time.Minute
+	// autoFaqChatCooldown - между любыми автоответами в чате
+	autoFaqChatCooldown = time.Minute
+	// autoFaqSnapshotTTL - вопросы чата перечитываются из базы не чаще, чем раз в это время
+	autoFaqSnapshotTTL = time.Minute
+	// autoFaqAnswerTTL - столько помним отправленный автоответ для кнопки "Не то"
+	autoFaqAnswerTTL = 24 * time.Hour
+)
+
+type autoFaqKey struct {
+	chatID  int64
+	entryID int64
+}
+
+// autoFaqMessage - сообщение с кнопкой "Не то"
+type autoFaqMessage struct {
+	chatID    int64
+	messageID int
+}
+
+// autoFaqAnswer - что и на какое сообщение ответил Морти. Если медиа ушло отдельно
+// от текста, кнопка висит на тексте, а удалять надо оба сообщения.
+type autoFaqAnswer struct {
+	entryID  int64
+	question string
+	parts    []int
+	sentAt   time.Time
+}
+
+// faqSnapshot - вопросы и отклонённые ответы чата, чтобы не ходить в базу на каждое сообщение
+type faqSnapshot struct {
+	entries    []*entity.FaqEntry
+	rejections []*entity.FaqRejection
+	loadedAt   time.Time
+}
+
+// FaqResponder сам отвечает на сообщения, похожие на вопросы из FAQ.
+// Работает только в чатах, где автоответы включили через /morty_autofaq.
+type FaqResponder struct {
+	FaqUseCase        *usecase.FaqUseCase
+	PermissionUseCase *usecase.PermissionUseCase
+	chatCache         *cache.ChatCache
+	i18n              *Localizer
+	snapshots         map[int64]*faqSnapshot
+	lastChatAnswer    map[int64]time.Time
+	lastEntryAnswer   map[autoFaqKey]time.Time
+	answers           map[autoFaqMessage]autoFaqAnswer
+	mu                sync.Mutex
+	logger            *logger.Logger
+}
+
+func NewFaqResponder(logger *logger.Logger, faqUseCase *usecase.FaqUseCase, permissionUseCase *usecase.PermissionUseCase, chatCache *cache.ChatCache, localizer *Localizer) *FaqResponder {
+	return &FaqResponder{
+		FaqUseCase:        faqUseCase,
+		PermissionUseCase: permissionUseCase,
+		chatCache:         chatCache,
+		i18n:              localizer,
+		snapshots:         make(map[int64]*faqSnapshot),
+		lastChatAnswer:    make(map[int64]time.Time),
+		lastEntryAnswer:   make(map[autoFaqKey]time.Time),
+		answers:           make(map[autoFaqMessage]autoFaqAnswer),
+		logger:            logger,
+	}
+}
+
+// HandleMessage возвращает true, если Морти ответил на сообщение из FAQ.
+func (h *FaqResponder) HandleMessage(ctx context.Context, b *bot.Bot, msg *models.Message) bool {
+	threshold := h.chatCache.GetAutoFaqThreshold(msg.Chat.ID)
+	if threshold <= 0 || msg.From == nil || msg.From.IsBot || msg.Text == "" || strings.HasPrefix(msg.Text, "/") {
+		return false
+	}
+
+	now := time.Now()
+	h.mu.Lock()
+	recent := now.Sub(h.lastChatAnswer[msg.Chat.ID]) < autoFaqChatCooldown
+	h.mu.Unlock()
+	if recent {
+		return false
+	}
+
+	snapshot, err := h.snapshot(ctx, msg.Chat.ID, now)
+	if err != nil {
+		h.logger.Error(ctx, "FaqResponder: load faq error", "chat", ChatForLogger(msg.Chat), "err", err)
+		return false
+	}
+	match := h.FaqUseCase.AutoMatch(snapshot.entries, snapshot.rejections, msg.Text, threshold)
+	if match == nil {
+		return false
+	}
+
+	key := autoFaqKey{chatID: msg.Chat.ID, entryID: match.Entry.ID}
+	h.mu.Lock()
+	if now.Sub(h.lastEntryAnswer[key]) < autoFaqEntryCooldown {
+		h.mu.Unlock()
+		h.logger.Debug(ctx, "FaqResponder: entry on cooldown", "entry", match.Entry.ID, "text", msg.Text, "chat", ChatForLogger(msg.Chat))
+		return false
+	}
+	h.lastEntryAnswer[key] = now
+	h.lastChatAnswer[msg.Chat.ID] = now
+	h.mu.Unlock()
+
+	text, err := RichTextFromFaqEntry(match.Entry)
+	if err != nil {
+		h.logger.Error(ctx, "FaqResponder: render entry error", "entry", match.Entry.ID, "chat", ChatForLogger(msg.Chat), "err", err)
+		return false
+	}
+	p := h.i18n.For(msg.Chat.ID, msg.From)
+	markup := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: p.T("autofaq.wrong"), CallbackData: AutoFaqCallbackPrefix + "wrong:" + strconv.FormatInt(match.Entry.ID, 10)},
+		}},
+	}
+	sent, err := SendRichTextParts(ctx, b, msg.Chat.ID, msg.MessageThreadID, msg.ID, text.WithHeader(p.T("autofaq.header")), markup)
+	if err != nil {
+		h.logger.Error(ctx, "FaqResponder: send answer error", "entry", match.Entry.ID, "chat", ChatForLogger(msg.Chat), "err", err)
+		return false
+	}
+	answer := autoFaqAnswer{entryID: match.Entry.ID, question: msg.Text, sentAt: now}
+	for _, part := range sent {
+		answer.parts = append(answer.parts, part.ID)
+	}
+	h.mu.Lock()
+	h.answers[autoFaqMessage{chatID: msg.Chat.ID, messageID: sent[len(sent)-1].ID}] = answer
+	h.mu.Unlock()
+	h.logger.Info(ctx, "FaqResponder: auto answer",
+		"entry", match.Entry.ID,
+		"score", match.Score,
+		"text", msg.Text,
+		"user", UserForLogger(msg.From),
+		"chat", ChatForLogger(msg.Chat),
+	)
+	h.cleanup(now)
+	return true
+}
+
+// HandleCallback - модератор нажал "Не то": ответ удаляется, а на похожие сообщения
+// этот вопрос в чате больше не предлагается
+func (h *FaqResponder) HandleCallback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	message := query.Message.Message
+	if message == nil {
+		b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+		return
+	}
+	p := h.i18n.For(message.Chat.ID, &query.From)
+	if err := h.PermissionUseCase.Authorize(ctx, message.Chat.ID, query.From.ID, entity.CapTuneFaq); err != nil {
+		b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            p.T("autofaq.moderators_only"),
+			ShowAlert:       true,
+		})
+		return
+	}
+
+	entryID, _ := strconv.ParseInt(strings.TrimPrefix(query.Data, AutoFaqCallbackPrefix+"wrong:"), 10, 64)
+
+	// Что спросили, берём из памяти, а после перезапуска - из сообщения, на которое ответили
+	key := autoFaqMessage{chatID: message.Chat.ID, messageID: message.ID}
+	h.mu.Lock()
+	answer, known := h.answers[key]
+	delete(h.answers, key)
+	h.mu.Unlock()
+	parts := []int{message.ID}
+	var question string
+	if known && answer.entryID == entryID {
+		question, parts = answer.question, answer.parts
+	} else if message.ReplyToMessage != nil {
+		question = message.ReplyToMessage.Text
+	}
+
+	// Кнопка могла прийти с чужим ID вопроса: отклоняем только вопрос, видимый в этом чате
+	entry, err := h.FaqUseCase.Get(ctx, message.Chat.ID, entryID)
+	switch {
+	case errors.Is(err, usecase.ErrFaqNotFound):
+		h.logger.Warn(ctx, "FaqResponder: entry is not in chat", "entry", entryID, "user", UserForLogger(&query.From), "chat", ChatForLogger(message.Chat))
+	case err != nil:
+		h.logger.Error(ctx, "FaqResponder: load entry error", "entry", entryID, "user", UserForLogger(&query.From), "chat", ChatForLogger(message.Chat), "err", err)
+	case question != "":
+		if err := h.FaqUseCase.Reject(ctx, message.Chat.ID, entry.ID, question, query.From.ID); err != nil {
+			h.logger.Error(ctx, "FaqResponder: save rejection error", "entry", entryID, "user", UserForLogger(&query.From), "chat", ChatForLogger(message.Chat), "err", err)
+		}
+		h.mu.Lock()
+		delete(h.snapshots, message.Chat.ID)
+		h.mu.Unlock()
+	}
+	h.logger.Info(ctx, "FaqResponder: answer rejected", "entry", entryID, "user", UserForLogger(&query.From), "chat", ChatForLogger(message.Chat))
+
+	for _, messageID := range parts {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    message.Chat.ID,
+			MessageID: messageID,
+		})
+	}
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            p.T("autofaq.rejected"),
+	})
+}
+
+func (h *FaqResponder) snapshot(ctx context.Context, chatID int64, now time.Time) (*faqSnapshot, error) {
+	h.mu.Lock()
+	snapshot, ok := h.snapshots[chatID]
+	h.mu.Unlock()
+	if ok && now.Sub(snapshot.loadedAt) < autoFaqSnapshotTTL {
+		return snapshot, nil
+	}
+
+	entries, err := h.FaqUseCase.List(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	rejections, err := h.FaqUseCase.Rejections(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot = &faqSnapshot{entries: entries, rejections: rejections, loadedAt: now}
+
+	h.mu.Lock()
+	h.snapshots[chatID] = snapshot
+	h.mu.Unlock()
+	return snapshot, nil
+}
+
+// cleanup убирает устаревшие отметки, чтобы карты не росли бесконечно
+func (h *FaqResponder) cleanup(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key, last := range h.lastEntryAnswer {
+		if now.Sub(last) >= autoFaqEntryCooldown {
+			delete(h.lastEntryAnswer, key)
+		}
+	}
+	for chatID, last := range h.lastChatAnswer {
+		if now.Sub(last) >= autoFaqChatCooldown {
+			delete(h.lastChatAnswer, chatID)
+		}
+	}
+	for key, answer := range h.answers {
+		if now.Sub(answer.sentAt) >= autoFaqAnswerTTL {
+			delete(h.answers, key)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/storage/cache"
+	"morty-smith-34-c/pkg/duration"
+	"morty-smith-34-c/pkg/i18n"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// DefaultLanguage - язык, на котором Морти говорил всегда
+const DefaultLanguage = "ru"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Localizer выбирает язык ответа: язык чата из /morty_lang, а если там auto
+// или чат не активирован - язык клиента Telegram пользователя.
+type Localizer struct {
+	bundle    *i18n.Bundle
+	chatCache *cache.ChatCache
+}
+
+// NewLocalizer загружает каталоги, вшитые в бинарник. Сломанный каталог - ошибка сборки,
+// поэтому паникуем сразу при старте, как и с шаблонами.
+func NewLocalizer(chatCache *cache.ChatCache) *Localizer {
+	bundle := i18n.NewBundle(DefaultLanguage)
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	// Сначала язык по умолчанию, чтобы он был первым в списках
+	for _, first := range []bool{true, false} {
+		for _, file := range files {
+			lang := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+			if (lang == DefaultLanguage) != first {
+				continue
+			}
+			data, err := localeFiles.ReadFile("locales/" + file.Name())
+			if err != nil {
+				panic(err)
+			}
+			if err := bundle.Add(lang, data); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return &Localizer{bundle: bundle, chatCache: chatCache}
+}
+
+// Languages - поддерживаемые языки, первым идёт язык по умолчанию
+func (l *Localizer) Languages() []string {
+	return l.bundle.Languages()
+}
+
+// Match подбирает поддерживаемый язык по коду вроде "en-US"
+func (l *Localizer) Match(code string) (string, bool) {
+	return l.bundle.Match(code)
+}
+
+// Lang возвращает язык, на котором отвечать пользователю в чате
+func (l *Localizer) Lang(chatID int64, user *models.User) string {
+	if lang, ok := l.chatCache.GetLanguage(chatID); ok && lang != entity.ChatLanguageAuto {
+		if lang, ok := l.bundle.Match(lang); ok {
+			return lang
+		}
+	}
+	if user != nil {
+		if lang, ok := l.bundle.Match(user.LanguageCode); ok {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// ChatLang - язык для сообщений всему чату, когда отвечать некому конкретному
+func (l *Localizer) ChatLang(chatID int64) string {
+	return l.Lang(chatID, nil)
+}
+
+// For - тексты для ответа пользователю в чате
+func (l *Localizer) For(chatID int64, user *models.User) Printer {
+	return l.Printer(l.Lang(chatID, user))
+}
+
+// Printer - тексты на конкретном языке
+func (l *Localizer) Printer(lang string) Printer {
+	return Printer{Lang: lang, bundle: l.bundle}
+}
+
+// Printer достаёт сообщения одного языка из каталога
+type Printer struct {
+	Lang   string
+	bundle *i18n.Bundle
+}
+
+// T - обычный текст без разметки
+func (p Printer) T(key string, args ...any) string {
+	return p.bundle.T(p.Lang, key, args...)
+}
+
+// N - текст, зависящий от числа: "%d минута", "%d минуты", "%d минут"
+func (p Printer) N(key string, n int, args ...any) string {
+	return p.bundle.N(p.Lang, key, int64(n), args...)
+}
+
+// Has проверяет, есть ли такой текст в каталоге
+func (p Printer) Has(key string) bool {
+	return p.bundle.Has(p.Lang, key)
+}
+
+// Markdown - текст для ParseModeMarkdown. В каталоге текст обычный, спецсимволы
+// экранируются здесь, а аргументы подставляются как есть: упоминания уже готовы,
+// строки от пользователей вызывающий экранирует сам.
+func (p Printer) Markdown(key string, args ...any) string {
+	return fmt.Sprintf(escapeFormat(p.bundle.Format(p.Lang, key)), args...)
+}
+
+// MarkdownN - Markdown для текста, зависящего от числа
+func (p Printer) MarkdownN(key string, n int, args ...any) string {
+	return fmt.Sprintf(escapeFormat(p.bundle.PluralFormat(p.Lang, key, int64(n))), append([]any{n}, args...)...)
+}
+
+// Duration выводит длительность словами: "1 день 2 часа", "навсегда"
+func (p Printer) Duration(d time.Duration) string {
+	if d == duration.Forever {
+		return p.T("duration.forever")
+	}
+	if d < time.Second {
+		return p.N("duration.seconds", 0)
+	}
+
+	units := []struct {
+		unit time.Duration
+		key  string
+	}{
+		{7 * 24 * time.Hour, "duration.weeks"},
+		{24 * time.Hour, "duration.days"},
+		{time.Hour, "duration.hours"},
+		{time.Minute, "duration.minutes"},
+		{time.Second, "duration.seconds"},
+	}
+	var out []string
+	for _, u := range units {
+		n := d / u.unit
+		if n == 0 {
+			continue
+		}
+		d -= n * u.unit
+		out = append(out, p.N(u.key, int(n)))
+	}
+	return strings.Join(out, " ")
+}
+
+// escapeFormat экранирует MarkdownV2 в формате, не трогая глаголы вроде %s и %[2]d
+func escapeFormat(format string) string {
+	var sb strings.Builder
+	literal := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		sb.WriteString(EscapeMarkdown(format[literal:i]))
+		end := i + 1
+		for end < len(format) && !isVerb(format[end]) {
+			end++
+		}
+		end = min(end+1, len(format))
+		sb.WriteString(format[i:end])
+		literal = end
+		i = end - 1
+	}
+	sb.WriteString(EscapeMarkdown(format[literal:]))
+	return sb.String()
+}
+
+// isVerb - буква глагола fmt или %%
+func isVerb(c byte) bool {
+	return c == '%' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
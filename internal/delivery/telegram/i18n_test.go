@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEscapeFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		args   []any
+		want   string // после fmt.Sprintf
+	}{
+		{"empty", "", nil, ""},
+		{"plain verb", "Мут на %s.", []any{"2 часа"}, `Мут на 2 часа\.`},
+		{"markdown around verb", "_жирный_ %s!", []any{"Морти"}, `\_жирный\_ Морти\!`},
+		{"percent sign", "100%% готово!", nil, `100% готово\!`},
+		{"indexed verbs", "%[2]s (%[1]d)", []any{7, "Морти"}, `Морти \(7\)`},
+		{"flags and width", "%-5d|", []any{42}, `42   \|`},
+		{"precision", "%.1f%%", []any{99.5}, `99.5%`},
+		{"plus flag", "%+d.", []any{3}, `+3\.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprintf(escapeFormat(tt.format), tt.args...); got != tt.want {
+				t.Errorf("Sprintf(escapeFormat(%q)) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeFormatTrailingPercent(t *testing.T) {
+	// Оборванный глагол в конце не должен потеряться или сломать разбор
+	if got := escapeFormat("скидка 5%"); got != "скидка 5%" {
+		t.Errorf("escapeFormat = %q, want it unchanged", got)
+	}
+}
+
+func TestIsVerb(t *testing.T) {
+	for _, c := range []byte("sdvqfxXT%") {
+		if !isVerb(c) {
+			t.Errorf("isVerb(%q) = false, want true", c)
+		}
+	}
+	for _, c := range []byte("[]-+#. 0123456789*") {
+		if isVerb(c) {
+			t.Errorf("isVerb(%q) = true, want false", c)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"context"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/storage/cache"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// MemberHandler следит, кто в каких чатах состоит. Заходы новеньких и проверку ника
+// отмечает UserHandler, здесь - обновления chat_member и сервисные сообщения о выходе.
+type MemberHandler struct {
+	ChatMemberUseCase *usecase.ChatMemberUseCase
+	UserUseCase       *usecase.UserUseCase
+	chatCache         *cache.ChatCache
+	userHandler       *UserHandler // ушедшему новичку больше не нужны таймер и отложенная проверка
+	logger            *logger.Logger
+}
+
+func NewMemberHandler(logger *logger.Logger, chatMemberUseCase *usecase.ChatMemberUseCase, userUseCase *usecase.UserUseCase, chatCache *cache.ChatCache, userHandler *UserHandler) *MemberHandler {
+	return &MemberHandler{
+		ChatMemberUseCase: chatMemberUseCase,
+		UserUseCase:       userUseCase,
+		chatCache:         chatCache,
+		userHandler:       userHandler,
+		logger:            logger,
+	}
+}
+
+// HandleChatMember обрабатывает обновление статуса участника.
+// Telegram присылает его, только если chat_member есть в allowed_updates и бот - админ.
+func (h *MemberHandler) HandleChatMember(ctx context.Context, b *bot.Bot, update *models.ChatMemberUpdated) {
+	if _, ok := h.chatCache.GetThreadID(update.Chat.ID); !ok {
+		return
+	}
+	user := memberUser(update.NewChatMember)
+	if user == nil || user.IsBot {
+		return
+	}
+
+	var err error
+	switch update.NewChatMember.Type {
+	case models.ChatMemberTypeOwner, models.ChatMemberTypeAdministrator, models.ChatMemberTypeMember:
+		err = h.joined(ctx, update.Chat.ID, user.ID)
+	case models.ChatMemberTypeRestricted:
+		if update.NewChatMember.Restricted != nil && update.NewChatMember.Restricted.IsMember {
+			err = h.joined(ctx, update.Chat.ID, user.ID)
+		} else {
+			h.userHandler.RemoveUserFromTimers(ctx, b, update.Chat.ID, user.ID)
+			err = h.ChatMemberUseCase.Left(ctx, update.Chat.ID, user.ID, entity.MemberStatusLeft)
+		}
+	case models.ChatMemberTypeBanned:
+		h.userHandler.RemoveUserFromTimers(ctx, b, update.Chat.ID, user.ID)
+		err = h.ChatMemberUseCase.Left(ctx, update.Chat.ID, user.ID, entity.MemberStatusBanned)
+	case models.ChatMemberTypeLeft:
+		h.userHandler.RemoveUserFromTimers(ctx, b, update.Chat.ID, user.ID)
+		status := entity.MemberStatusLeft
+		// Удаление из чата - это бан и разбан, или выход по воле админа
+		if update.OldChatMember.Type == models.ChatMemberTypeBanned || update.From.ID != user.ID {
+			status = entity.MemberStatusKicked
+		}
+		err = h.ChatMemberUseCase.Left(ctx, update.Chat.ID, user.ID, status)
+	}
+	if err != nil {
+		h.logger.Error(ctx, "HandleChatMember: failed to track member",
+			"user", UserForLogger(user),
+			"chat", ChatForLogger(update.Chat),
+			"status", string(update.NewChatMember.Type),
+			"err", err,
+		)
+	}
+}
+
+// HandleLeftMessage отмечает выход по сервисному сообщению, если chat_member не пришёл
+func (h *MemberHandler) HandleLeftMessage(ctx context.Context, b *bot.Bot, msg *models.Message) {
+	user := msg.LeftChatMember
+	if user == nil || user.IsBot {
+		return
+	}
+	h.userHandler.RemoveUserFromTimers(ctx, b, msg.Chat.ID, user.ID)
+	status := entity.MemberStatusLeft
+	if msg.From != nil && msg.From.ID != user.ID {
+		status = entity.MemberStatusKicked
+	}
+	if err := h.ChatMemberUseCase.Left(ctx, msg.Chat.ID, user.ID, status); err != nil {
+		h.logger.Error(ctx, "HandleLeftMessage: failed to track member",
+			"user", UserForLogger(user),
+			"chat", ChatForLogger(msg.Chat),
+			"err", err,
+		)
+	}
+}
+
+// joined отмечает заход, проверенным считается тот, кого мы уже знаем
+func (h *MemberHandler) joined(ctx context.Context, chatID, telegramID int64) error {
+	verified, err := h.UserUseCase.Exists(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	return h.ChatMemberUseCase.Joined(ctx, chatID, telegramID, verified)
+}
+
+// memberUser достаёт пользователя из любого варианта ChatMember
+func memberUser(member models.ChatMember) *models.User {
+	switch member.Type {
+	case models.ChatMemberTypeOwner:
+		if member.Owner != nil {
+			return member.Owner.User
+		}
+	case models.ChatMemberTypeAdministrator:
+		if member.Administrator != nil {
+			return &member.Administrator.User
+		}
+	case models.ChatMemberTypeMember:
+		if member.Member != nil {
+			return member.Member.User
+		}
+	case models.ChatMemberTypeRestricted:
+		if member.Restricted != nil {
+			return member.Restricted.User
+		}
+	case models.ChatMemberTypeLeft:
+		if member.Left != nil {
+			return member.Left.User
+		}
+	case models.ChatMemberTypeBanned:
+		if member.Banned != nil {
+			return member.Banned.User
+		}
+	}
+	return nil
+}
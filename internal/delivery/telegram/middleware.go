@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	"context"
+
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/storage/cache"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// RememberUsersMiddleware запоминает username всех авторов сообщений,
+// чтобы модераторы могли указывать цель команды через @username.
+// Новые username проверенных пользователей сохраняются в базу, чтобы пережить перезапуск.
+func RememberUsersMiddleware(log *logger.Logger, userCache *cache.UserCache, userUseCase *usecase.UserUseCase) bot.Middleware {
+	remember := func(ctx context.Context, user *models.User) {
+		if !userCache.Remember(user) {
+			return
+		}
+		if err := userUseCase.RememberUsername(ctx, user.ID, user.Username); err != nil {
+			log.Error(ctx, "RememberUsersMiddleware: save username error", "user", UserForLogger(user), "err", err)
+		}
+	}
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if update.Message != nil {
+				remember(ctx, update.Message.From)
+				if update.Message.ReplyToMessage != nil {
+					remember(ctx, update.Message.ReplyToMessage.From)
+				}
+				for i := range update.Message.NewChatMembers {
+					remember(ctx, &update.Message.NewChatMembers[i])
+				}
+			}
+			next(ctx, b, update)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// NightModeScheduler раз в минуту сверяет расписания ночного режима с текущим временем.
+// Состояние (применены ли ограничения и какие права были до них) хранится в базе,
+// поэтому после перезапуска бот продолжает с того же места.
+type NightModeScheduler struct {
+	NightModeUseCase *usecase.NightModeUseCase
+	interval         time.Duration
+	logger           *logger.Logger
+}
+
+func NewNightModeScheduler(logger *logger.Logger, nightModeUseCase *usecase.NightModeUseCase) *NightModeScheduler {
+	return &NightModeScheduler{
+		NightModeUseCase: nightModeUseCase,
+		interval:         time.Minute,
+		logger:           logger,
+	}
+}
+
+// Start запускает планировщик в отдельной горутине.
+func (s *NightModeScheduler) Start(ctx context.Context, b *bot.Bot) {
+	go func() {
+		s.logger.Info(ctx, "Starting night mode scheduler")
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.sync(ctx, b)
+		for {
+			select {
+			case <-ticker.C:
+				s.sync(ctx, b)
+			case <-ctx.Done():
+				s.logger.Info(ctx, "Stopping night mode scheduler")
+				return
+			}
+		}
+	}()
+}
+
+func (s *NightModeScheduler) sync(ctx context.Context, b *bot.Bot) {
+	nightModes, err := s.NightModeUseCase.GetScheduled(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to load schedules", "err", err)
+		return
+	}
+
+	now := time.Now()
+	for _, nightMode := range nightModes {
+		shouldBeActive := s.NightModeUseCase.ShouldBeActive(nightMode, now)
+		switch {
+		case shouldBeActive && !nightMode.Active:
+			s.apply(ctx, b, nightMode)
+		case !shouldBeActive && nightMode.Active:
+			s.restore(ctx, b, nightMode)
+		}
+	}
+}
+
+// apply запоминает текущие права чата и урезает их согласно расписанию.
+func (s *NightModeScheduler) apply(ctx context.Context, b *bot.Bot, nightMode *entity.NightMode) {
+	chat, err := b.GetChat(ctx, &bot.GetChatParams{ChatID: nightMode.ChatID})
+	if err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to get chat", "chat_id", nightMode.ChatID, "err", err)
+		return
+	}
+	permissions := defaultChatPermissions()
+	if chat.Permissions != nil {
+		permissions = *chat.Permissions
+	}
+	saved, err := json.Marshal(permissions)
+	if err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to marshal permissions", "chat_id", nightMode.ChatID, "err", err)
+		return
+	}
+
+	_, err = b.SetChatPermissions(ctx, &bot.SetChatPermissionsParams{
+		ChatID:      nightMode.ChatID,
+		Permissions: nightPermissions(permissions, nightMode.Restriction),
+	})
+	if err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to apply night mode", "chat_id", nightMode.ChatID, "err", err)
+		return
+	}
+	if err := s.NightModeUseCase.MarkApplied(ctx, nightMode.ChatID, string(saved)); err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to save state", "chat_id", nightMode.ChatID, "err", err)
+		return
+	}
+	s.logger.Info(ctx, "NightModeScheduler: night mode applied", "chat_id", nightMode.ChatID, "restriction", nightMode.Restriction)
+}
+
+// restore возвращает права, сохранённые при включении ночного режима.
+func (s *NightModeScheduler) restore(ctx context.Context, b *bot.Bot, nightMode *entity.NightMode) {
+	permissions := defaultChatPermissions()
+	if nightMode.SavedPermissions != nil {
+		if err := json.Unmarshal([]byte(*nightMode.SavedPermissions), &permissions); err != nil {
+			s.logger.Error(ctx, "NightModeScheduler: broken saved permissions, using defaults", "chat_id", nightMode.ChatID, "err", err)
+			permissions = defaultChatPermissions()
+		}
+	}
+
+	_, err := b.SetChatPermissions(ctx, &bot.SetChatPermissionsParams{
+		ChatID:      nightMode.ChatID,
+		Permissions: permissions,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to restore permissions", "chat_id", nightMode.ChatID, "err", err)
+		return
+	}
+	if err := s.NightModeUseCase.MarkRestored(ctx, nightMode.ChatID); err != nil {
+		s.logger.Error(ctx, "NightModeScheduler: failed to save state", "chat_id", nightMode.ChatID, "err", err)
+		return
+	}
+	s.logger.Info(ctx, "NightModeScheduler: night mode restored", "chat_id", nightMode.ChatID)
+}
+
+// nightPermissions урезает права чата: media - только текст, all - ничего.
+func nightPermissions(permissions models.ChatPermissions, restriction string) models.ChatPermissions {
+	permissions.CanSendAudios = false
+	permissions.CanSendDocuments = false
+	permissions.CanSendPhotos = false
+	permissions.CanSendVideos = false
+	permissions.CanSendVideoNotes = false
+	permissions.CanSendVoiceNotes = false
+	permissions.CanSendPolls = false
+	permissions.CanSendOtherMessages = false
+	permissions.CanAddWebPagePreviews = false
+	if restriction == entity.NightRestrictionAll {
+		permissions.CanSendMessages = false
+	}
+	return permissions
+}
+
+func defaultChatPermissions() models.ChatPermissions {
+	return models.ChatPermissions{
+		CanSendMessages:       true,
+		CanSendAudios:         true,
+		CanSendDocuments:      true,
+		CanSendPhotos:         true,
+		CanSendVideos:         true,
+		CanSendVideoNotes:     true,
+		CanSendVoiceNotes:     true,
+		CanSendPolls:          true,
+		CanSendOtherMessages:  true,
+		CanAddWebPagePreviews: true,
+	}
+}
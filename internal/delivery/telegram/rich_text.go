@@ -0,0 +1,315 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"morty-smith-34-c/internal/app/entity"
+	"strings"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// captionLimit - сколько символов UTF-16 Telegram разрешает в подписи к медиа
+const captionLimit = 1024
+
+// RichText - текст с разметкой Telegram и, может быть, медиа.
+// Разметка лежит в MessageEntity, поэтому ничего экранировать не нужно.
+type RichText struct {
+	Text        string
+	Entities    []models.MessageEntity
+	MediaType   string // entity.MediaPhoto, entity.MediaVideo..., пусто - без медиа
+	MediaFileID string
+}
+
+// IsEmpty - нечего ни показать, ни сохранить
+func (t RichText) IsEmpty() bool {
+	return strings.TrimSpace(t.Text) == "" && t.MediaFileID == ""
+}
+
+// RichTextFromCommand берёт текст после команды вместе с его разметкой
+func RichTextFromCommand(msg *models.Message) RichText {
+	payload := CommandPayload(msg.Text)
+	if payload == "" {
+		return RichText{}
+	}
+	// CommandPayload обрезает пробелы, значит payload - хвост текста без пробелов в конце
+	end := len(strings.TrimRightFunc(msg.Text, unicode.IsSpace))
+	start := end - len(payload)
+	return RichText{
+		Text:     payload,
+		Entities: sliceEntities(msg.Entities, UTF16Len(msg.Text[:start]), UTF16Len(msg.Text[:end])),
+	}
+}
+
+// RichTextFromMessage копирует сообщение целиком: текст или подпись, разметку и медиа
+func RichTextFromMessage(msg *models.Message) RichText {
+	text := RichText{Text: msg.Text, Entities: msg.Entities}
+	if msg.Text == "" {
+		text.Text = msg.Caption
+		text.Entities = msg.CaptionEntities
+	}
+	switch {
+	case len(msg.Photo) > 0:
+		// Telegram присылает несколько размеров, последний - самый большой
+		text.MediaType = entity.MediaPhoto
+		text.MediaFileID = msg.Photo[len(msg.Photo)-1].FileID
+	case msg.Video != nil:
+		text.MediaType = entity.MediaVideo
+		text.MediaFileID = msg.Video.FileID
+	case msg.Animation != nil:
+		// У гифок Telegram заполняет и Document, поэтому проверяем их раньше
+		text.MediaType = entity.MediaAnimation
+		text.MediaFileID = msg.Animation.FileID
+	case msg.Document != nil:
+		text.MediaType = entity.MediaDocument
+		text.MediaFileID = msg.Document.FileID
+	}
+	return text
+}
+
+// RichTextFromEntity восстанавливает текст из базы
+func RichTextFromEntity(text *entity.ChatText) (RichText, error) {
+	result := RichText{Text: text.Text}
+	if text.Entities != nil {
+		if err := json.Unmarshal([]byte(*text.Entities), &result.Entities); err != nil {
+			return RichText{}, fmt.Errorf("failed to decode entities: %w", err)
+		}
+	}
+	if text.MediaType != nil && text.MediaFileID != nil {
+		result.MediaType = *text.MediaType
+		result.MediaFileID = *text.MediaFileID
+	}
+	return result, nil
+}
+
+// RichTextFromFaqEntry - вопрос жирным, под ним ответ с разметкой
+func RichTextFromFaqEntry(entry *entity.FaqEntry) (RichText, error) {
+	answer := RichText{Text: entry.Answer}
+	if entry.AnswerEntities != nil {
+		if err := json.Unmarshal([]byte(*entry.AnswerEntities), &answer.Entities); err != nil {
+			return RichText{}, fmt.Errorf("failed to decode entities: %w", err)
+		}
+	}
+	if entry.MediaType != nil && entry.MediaFileID != nil {
+		answer.MediaType = *entry.MediaType
+		answer.MediaFileID = *entry.MediaFileID
+	}
+	question := "❓ " + entry.Question
+	return answer.WithHeader(question+"\n\n", models.MessageEntity{
+		Type:   models.MessageEntityTypeBold,
+		Offset: 0,
+		Length: UTF16Len(question),
+	}), nil
+}
+
+// ToEntity готовит текст к сохранению в базу
+func (t RichText) ToEntity(chatID int64, kind string, updatedBy int64) (*entity.ChatText, error) {
+	text := &entity.ChatText{
+		ChatID:    chatID,
+		Kind:      kind,
+		Text:      t.Text,
+		UpdatedBy: &updatedBy,
+	}
+	if len(t.Entities) > 0 {
+		data, err := json.Marshal(t.Entities)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode entities: %w", err)
+		}
+		entities := string(data)
+		text.Entities = &entities
+	}
+	if t.MediaFileID != "" {
+		text.MediaType = &t.MediaType
+		text.MediaFileID = &t.MediaFileID
+	}
+	return text, nil
+}
+
+// WithMention ставит перед текстом упоминание пользователя и подпись к нему,
+// разметка текста сдвигается следом
+func (t RichText) WithMention(user *models.User, suffix string) RichText {
+	name := mentionName(user)
+	return t.WithHeader(name+suffix, models.MessageEntity{
+		Type:   models.MessageEntityTypeTextMention,
+		Offset: 0,
+		Length: UTF16Len(name),
+		User:   user,
+	})
+}
+
+// WithHeader ставит перед текстом заголовок с его собственной разметкой
+func (t RichText) WithHeader(header string, headerEntities ...models.MessageEntity) RichText {
+	shift := UTF16Len(header)
+	entities := make([]models.MessageEntity, 0, len(t.Entities)+len(headerEntities))
+	entities = append(entities, headerEntities...)
+	for _, e := range t.Entities {
+		e.Offset += shift
+		entities = append(entities, e)
+	}
+	t.Text = header + t.Text
+	t.Entities = entities
+	return t
+}
+
+// Slice вырезает кусок текста [from, to) в байтах вместе с разметкой.
+// Пробелы по краям куска отбрасываются.
+func (t RichText) Slice(from, to int) RichText {
+	part := t.Text[from:to]
+	from += len(part) - len(strings.TrimLeftFunc(part, unicode.IsSpace))
+	to = from + len(strings.TrimSpace(part))
+	return RichText{
+		Text:     t.Text[from:to],
+		Entities: sliceEntities(t.Entities, UTF16Len(t.Text[:from]), UTF16Len(t.Text[:to])),
+	}
+}
+
+// SendRichText отправляет текст как есть, а медиа - с текстом в подписи.
+// Если подпись не влезает в лимит Telegram, текст уходит отдельным сообщением после медиа.
+// Возвращает последнее отправленное сообщение, к нему же крепится клавиатура.
+func SendRichText(ctx context.Context, b *bot.Bot, chatID int64, threadID, replyTo int, text RichText, markup models.ReplyMarkup) (*models.Message, error) {
+	sent, err := SendRichTextParts(ctx, b, chatID, threadID, replyTo, text, markup)
+	if err != nil {
+		return nil, err
+	}
+	return sent[len(sent)-1], nil
+}
+
+// SendRichTextParts - то же, что SendRichText, но возвращает все отправленные сообщения:
+// медиа и текст после него, если подпись не влезла. Оба отвечают на replyTo.
+func SendRichTextParts(ctx context.Context, b *bot.Bot, chatID int64, threadID, replyTo int, text RichText, markup models.ReplyMarkup) ([]*models.Message, error) {
+	var reply *models.ReplyParameters
+	if replyTo != 0 {
+		reply = &models.ReplyParameters{MessageID: replyTo}
+	}
+	if text.MediaFileID == "" {
+		sent, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          chatID,
+			MessageThreadID: threadID,
+			Text:            text.Text,
+			Entities:        text.Entities,
+			ReplyParameters: reply,
+			ReplyMarkup:     markup,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Message{sent}, nil
+	}
+
+	caption, entities, mediaMarkup := text.Text, text.Entities, markup
+	tooLong := UTF16Len(text.Text) > captionLimit
+	if tooLong {
+		caption, entities, mediaMarkup = "", nil, nil
+	}
+	media, err := sendMedia(ctx, b, chatID, threadID, reply, text.MediaType, text.MediaFileID, caption, entities, mediaMarkup)
+	if err != nil {
+		return nil, err
+	}
+	if !tooLong {
+		return []*models.Message{media}, nil
+	}
+	sent, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          chatID,
+		MessageThreadID: threadID,
+		Text:            text.Text,
+		Entities:        text.Entities,
+		ReplyParameters: reply,
+		ReplyMarkup:     markup,
+	})
+	if err != nil {
+		return []*models.Message{media}, err
+	}
+	return []*models.Message{media, sent}, nil
+}
+
+func sendMedia(ctx context.Context, b *bot.Bot, chatID int64, threadID int, reply *models.ReplyParameters, mediaType, fileID, caption string, entities []models.MessageEntity, markup models.ReplyMarkup) (*models.Message, error) {
+	file := &models.InputFileString{Data: fileID}
+	switch mediaType {
+	case entity.MediaPhoto:
+		return b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:          chatID,
+			MessageThreadID: threadID,
+			Photo:           file,
+			Caption:         caption,
+			CaptionEntities: entities,
+			ReplyParameters: reply,
+			ReplyMarkup:     markup,
+		})
+	case entity.MediaVideo:
+		return b.SendVideo(ctx, &bot.SendVideoParams{
+			ChatID:          chatID,
+			MessageThreadID: threadID,
+			Video:           file,
+			Caption:         caption,
+			CaptionEntities: entities,
+			ReplyParameters: reply,
+			ReplyMarkup:     markup,
+		})
+	case entity.MediaAnimation:
+		return b.SendAnimation(ctx, &bot.SendAnimationParams{
+			ChatID:          chatID,
+			MessageThreadID: threadID,
+			Animation:       file,
+			Caption:         caption,
+			CaptionEntities: entities,
+			ReplyParameters: reply,
+			ReplyMarkup:     markup,
+		})
+	case entity.MediaDocument:
+		return b.SendDocument(ctx, &bot.SendDocumentParams{
+			ChatID:          chatID,
+			MessageThreadID: threadID,
+			Document:        file,
+			Caption:         caption,
+			CaptionEntities: entities,
+			ReplyParameters: reply,
+			ReplyMarkup:     markup,
+		})
+	}
+	return nil, fmt.Errorf("unknown media type: %s", mediaType)
+}
+
+// sliceEntities оставляет разметку внутри [start, end) и сдвигает её к началу
+func sliceEntities(entities []models.MessageEntity, start, end int) []models.MessageEntity {
+	var result []models.MessageEntity
+	for _, e := range entities {
+		from := max(e.Offset, start)
+		to := min(e.Offset+e.Length, end)
+		if to <= from {
+			continue
+		}
+		e.Offset = from - start
+		e.Length = to - from
+		result = append(result, e)
+	}
+	return result
+}
+
+// UTF16Len - длина строки в единицах UTF-16, в них Telegram считает смещения разметки
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// mentionName - как назвать пользователя в упоминании
+func mentionName(user *models.User) string {
+	switch {
+	case user.FirstName != "" && user.LastName != "":
+		return user.FirstName + " " + user.LastName
+	case user.FirstName != "":
+		return user.FirstName
+	case user.Username != "":
+		return "@" + user.Username
+	}
+	return "User"
+}
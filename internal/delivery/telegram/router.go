@@ -0,0 +1,209 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// CommandFunc обрабатывает команду. args[0] - имя команды без @botname, дальше аргументы.
+type CommandFunc func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string)
+
+// CommandMiddleware оборачивает обработчик команды, cmd - описание вызванной команды.
+type CommandMiddleware func(cmd *Command, next CommandFunc) CommandFunc
+
+// Command - описание команды для роутера. Описание и пример вызова лежат в каталоге
+// под ключами command.<имя> и command.<имя>.usage.
+type Command struct {
+	Name       string            // без слеша: "mute"
+	Aliases    []string          // другие имена той же команды
+	Capability entity.Capability // нужное право, пусто - команда доступна всем
+	ChatTypes  []models.ChatType // где команда работает, пусто - везде
+	MinArgs    int               // сколько аргументов нужно после команды
+	Hidden     bool              // не показывать в /help и меню
+	Handler    CommandFunc
+}
+
+// Description - коротко, что делает команда, для /help и меню
+func (c *Command) Description(p Printer) string {
+	return p.T("command." + c.Name)
+}
+
+// Usage - пример вызова, пусто - примера нет
+func (c *Command) Usage(p Printer) string {
+	key := "command." + c.Name + ".usage"
+	if !p.Has(key) {
+		return ""
+	}
+	return p.T(key)
+}
+
+// AllowedIn проверяет, работает ли команда в чате такого типа
+func (c *Command) AllowedIn(chatType models.ChatType) bool {
+	if len(c.ChatTypes) == 0 {
+		return true
+	}
+	for _, t := range c.ChatTypes {
+		if t == chatType {
+			return true
+		}
+	}
+	return false
+}
+
+// Router находит команду по точному имени и вызывает её через цепочку middleware.
+// В отличие от префиксных обработчиков, /mutefoo не вызовет /mute,
+// а /mute@other_bot не вызовет ничего.
+type Router struct {
+	commands    map[string]*Command
+	ordered     []*Command
+	middlewares []CommandMiddleware
+	botUsername string
+	i18n        *Localizer
+	logger      *logger.Logger
+}
+
+func NewRouter(logger *logger.Logger, botUsername string, localizer *Localizer) *Router {
+	return &Router{
+		commands:    make(map[string]*Command),
+		botUsername: botUsername,
+		i18n:        localizer,
+		logger:      logger,
+	}
+}
+
+// Use добавляет middleware. Первый добавленный вызывается первым.
+func (r *Router) Use(middlewares ...CommandMiddleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Register добавляет команды. Имена и алиасы не должны повторяться.
+func (r *Router) Register(commands ...Command) {
+	for i := range commands {
+		cmd := &commands[i]
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			name = strings.ToLower(name)
+			if _, exists := r.commands[name]; exists {
+				panic("router: duplicate command /" + name)
+			}
+			r.commands[name] = cmd
+		}
+		r.ordered = append(r.ordered, cmd)
+	}
+}
+
+// Commands возвращает зарегистрированные команды в порядке регистрации
+func (r *Router) Commands() []*Command {
+	return r.ordered
+}
+
+// Lookup ищет команду по имени или алиасу, слеш и @botname необязательны
+func (r *Router) Lookup(name string) (*Command, bool) {
+	name = strings.TrimPrefix(strings.ToLower(name), "/")
+	if at := strings.IndexByte(name, '@'); at >= 0 {
+		name = name[:at]
+	}
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// Match подходит для bot.RegisterHandlerMatchFunc
+func (r *Router) Match(update *models.Update) bool {
+	if update.Message == nil {
+		return false
+	}
+	_, _, ok := r.find(update.Message.Text)
+	return ok
+}
+
+// Handle вызывает найденную команду
+func (r *Router) Handle(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.Message
+	if msg == nil || msg.From == nil {
+		return
+	}
+	cmd, args, ok := r.find(msg.Text)
+	if !ok {
+		return
+	}
+	if !cmd.AllowedIn(msg.Chat.Type) {
+		r.logger.Debug(ctx, "Router: wrong chat type",
+			"text", msg.Text,
+			"user", UserForLogger(msg.From),
+			"chat", ChatForLogger(msg.Chat),
+		)
+		return
+	}
+
+	handler := r.checkArgs(cmd, cmd.Handler)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](cmd, handler)
+	}
+	handler(ctx, b, msg, args)
+}
+
+// checkArgs отвечает примером вызова, если аргументов не хватает
+func (r *Router) checkArgs(cmd *Command, next CommandFunc) CommandFunc {
+	return func(ctx context.Context, b *bot.Bot, msg *models.Message, args []string) {
+		if len(args)-1 >= cmd.MinArgs {
+			next(ctx, b, msg, args)
+			return
+		}
+		r.logger.Debug(ctx, "Router: missing args",
+			"text", msg.Text,
+			"user", UserForLogger(msg.From),
+			"chat", ChatForLogger(msg.Chat),
+		)
+		p := r.i18n.For(msg.Chat.ID, msg.From)
+		text := p.T("router.missing_args")
+		if usage := cmd.Usage(p); usage != "" {
+			text += " " + p.T("router.example", usage)
+		}
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          msg.Chat.ID,
+			MessageThreadID: msg.MessageThreadID,
+			Text:            text,
+			ReplyParameters: &models.ReplyParameters{
+				MessageID: msg.ID,
+			},
+		})
+	}
+}
+
+// find разбирает текст команды и ищет её среди зарегистрированных
+func (r *Router) find(text string) (*Command, []string, bool) {
+	args := strings.Fields(text)
+	if len(args) == 0 || !strings.HasPrefix(args[0], "/") {
+		return nil, nil, false
+	}
+	name := strings.TrimPrefix(args[0], "/")
+	if at := strings.IndexByte(name, '@'); at >= 0 {
+		// Команда адресована другому боту
+		if !strings.EqualFold(name[at+1:], r.botUsername) {
+			return nil, nil, false
+		}
+		name = name[:at]
+	}
+	cmd, ok := r.commands[strings.ToLower(name)]
+	if !ok {
+		return nil, nil, false
+	}
+	args[0] = "/" + cmd.Name
+	return cmd, args, true
+}
+
+// CommandPayload возвращает текст после команды с сохранением переносов строк
+func CommandPayload(text string) string {
+	text = strings.TrimLeftFunc(text, unicode.IsSpace)
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end < 0 {
+		return ""
+	}
+	return strings.TrimSpace(text[end:])
+}
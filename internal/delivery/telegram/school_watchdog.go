@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"morty-smith-34-c/internal/school"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+)
+
+// SchoolWatchdog следит за предохранителем School API. Когда API ложится, он сообщает
+// модераторам и пробует API, пока тот не оживёт, а потом проверяет отложенные ники.
+// Таймеры новичков, пока API лежит, продлевает сам UserHandler.
+type SchoolWatchdog struct {
+	breaker       *school.Breaker
+	schoolClient  *school.Client
+	userHandler   *UserHandler
+	i18n          *Localizer
+	alertChatID   int64 // 0 - только в лог
+	probeInterval time.Duration
+	logger        *logger.Logger
+}
+
+func NewSchoolWatchdog(logger *logger.Logger, breaker *school.Breaker, schoolClient *school.Client, userHandler *UserHandler, localizer *Localizer, alertChatID int64) *SchoolWatchdog {
+	return &SchoolWatchdog{
+		breaker:       breaker,
+		schoolClient:  schoolClient,
+		userHandler:   userHandler,
+		i18n:          localizer,
+		alertChatID:   alertChatID,
+		probeInterval: 15 * time.Second,
+		logger:        logger,
+	}
+}
+
+// Start запускает слежку в отдельной горутине.
+func (w *SchoolWatchdog) Start(ctx context.Context, b *bot.Bot) {
+	// Смены состояния схлопываются: важно только, лежит API сейчас или нет
+	changed := make(chan struct{}, 1)
+	w.breaker.OnChange(func(from, to school.BreakerState) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	go func() {
+		w.logger.Info(ctx, "Starting School API watchdog")
+		ticker := time.NewTicker(w.probeInterval)
+		defer ticker.Stop()
+
+		down := false
+		for {
+			select {
+			case <-changed:
+			case <-ticker.C:
+				if down {
+					// Без трафика предохранитель сам не замкнётся, нужен пробный запрос
+					w.schoolClient.Ping(ctx)
+				}
+			case <-ctx.Done():
+				w.logger.Info(ctx, "Stopping School API watchdog")
+				return
+			}
+
+			// Полуоткрытый предохранитель - ещё не починка, ждём результата пробы
+			switch state := w.breaker.State(); {
+			case state == school.BreakerOpen && !down:
+				down = true
+				w.logger.Warn(ctx, "SchoolWatchdog: School API is down, degraded mode on")
+				w.alert(ctx, b, "school_api.down")
+			case state == school.BreakerClosed && down:
+				down = false
+				deferred := w.userHandler.DeferredCount()
+				w.logger.Info(ctx, "SchoolWatchdog: School API is back, degraded mode off", "deferred", deferred)
+				w.alert(ctx, b, "school_api.up", deferred)
+				w.userHandler.ReplayDeferred(ctx, b)
+			}
+		}
+	}()
+}
+
+// alert пишет модераторам в чат для оповещений
+func (w *SchoolWatchdog) alert(ctx context.Context, b *bot.Bot, key string, args ...any) {
+	if w.alertChatID == 0 {
+		return
+	}
+	p := w.i18n.Printer(w.i18n.ChatLang(w.alertChatID))
+	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: w.alertChatID,
+		Text:   p.T(key, args...),
+	}); err != nil {
+		w.logger.Error(ctx, "SchoolWatchdog: failed to send alert", "chat", w.alertChatID, "err", err)
+	}
+}
@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/internal/storage/cache"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// MaxSlowModeDelay - больше часа между сообщениями уже не медленный режим, а бан.
+const MaxSlowModeDelay = 3600
+
+type slowModeKey struct {
+	chatID int64
+	userID int64
+}
+
+// SlowModeHandler удаляет сообщения, отправленные чаще, чем разрешает медленный режим чата.
+// Telegram не даёт ботам включать slow mode через API, поэтому следим сами.
+type SlowModeHandler struct {
+	PermissionUseCase *usecase.PermissionUseCase
+	chatCache         *cache.ChatCache
+	lastMessages      map[slowModeKey]time.Time
+	mu                sync.Mutex
+	logger            *logger.Logger
+}
+
+func NewSlowModeHandler(logger *logger.Logger, permissionUseCase *usecase.PermissionUseCase, chatCache *cache.ChatCache) *SlowModeHandler {
+	return &SlowModeHandler{
+		PermissionUseCase: permissionUseCase,
+		chatCache:         chatCache,
+		lastMessages:      make(map[slowModeKey]time.Time),
+		logger:            logger,
+	}
+}
+
+// HandleMessage возвращает true, если сообщение удалено из-за медленного режима.
+func (h *SlowModeHandler) HandleMessage(ctx context.Context, b *bot.Bot, msg *models.Message) bool {
+	delay := time.Duration(h.chatCache.GetSlowModeDelay(msg.Chat.ID)) * time.Second
+	if delay <= 0 || msg.From == nil || msg.SenderChat != nil {
+		return false
+	}
+
+	key := slowModeKey{chatID: msg.Chat.ID, userID: msg.From.ID}
+	now := time.Now()
+
+	h.mu.Lock()
+	last, exists := h.lastMessages[key]
+	if !exists || now.Sub(last) >= delay {
+		h.lastMessages[key] = now
+		h.cleanup(now)
+		h.mu.Unlock()
+		return false
+	}
+	h.mu.Unlock()
+
+	// Модераторов медленный режим не касается, права проверяем только для нарушителей
+	if err := h.PermissionUseCase.Authorize(ctx, msg.Chat.ID, msg.From.ID, entity.CapBypassSlowMode); err == nil {
+		return false
+	}
+
+	h.logger.Debug(ctx, "SlowModeHandler: delete message",
+		"text", msg.Text,
+		"user", UserForLogger(msg.From),
+		"chat", ChatForLogger(msg.Chat),
+	)
+	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+	})
+	return true
+}
+
+// cleanup выкидывает устаревшие записи, чтобы карта не росла бесконечно. Вызывать под h.mu.
+func (h *SlowModeHandler) cleanup(now time.Time) {
+	if len(h.lastMessages) < 1000 {
+		return
+	}
+	for key, last := range h.lastMessages {
+		if now.Sub(last) > MaxSlowModeDelay*time.Second {
+			delete(h.lastMessages, key)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package telegram
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/pkg/logger"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Шаблоны по умолчанию, по файлу на язык: templates/ru.tmpl, templates/en.tmpl
+//
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// MaxTemplateLen - длиннее шаблон уже не сообщение, а поэма
+const MaxTemplateLen = 2000
+
+// Markdown - готовый кусок MarkdownV2, шаблон вставляет его как есть
+type Markdown string
+
+// TemplateVars - переменные шаблона. Строки экранируются при подстановке,
+// Markdown вставляется как есть, числа и bool - без изменений.
+type TemplateVars map[string]any
+
+// TemplateInfo - шаблон, который можно переопределить в чате.
+// Описание и список переменных лежат в каталоге: template.<имя>.about и template.<имя>.vars.
+type TemplateInfo struct {
+	Name   string
+	Sample func(user *models.User, p Printer) TemplateVars // данные для превью
+}
+
+// Templates - все шаблоны, которые можно переопределить в чате
+var Templates = []TemplateInfo{
+	{
+		Name: "welcome",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"User": Mention(user), "Topic": Markdown("[ID](https://t.me/c/1/1)"), "Minutes": 5}
+		},
+	},
+	{
+		Name: "welcome_back",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"User": Mention(user)}
+		},
+	},
+	{
+		Name: "nick_not_found",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"User": Mention(user), "Nick": "brieyele"}
+		},
+	},
+	{
+		Name: "nick_check_failed",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"User": Mention(user), "Nick": "brieyele"}
+		},
+	},
+	{
+		Name: "nick_check_delayed",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"User": Mention(user), "Nick": "brieyele"}
+		},
+	},
+	{
+		Name: "nick_ok",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"User": Mention(user), "Nick": "brieyele"}
+		},
+	},
+	{
+		Name: "mute",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"Target": Mention(user), "Admin": Mention(user), "Duration": p.Duration(2 * time.Hour), "Forever": false, "Reason": p.T("template.sample_reason")}
+		},
+	},
+	{
+		Name: "unmute",
+		Sample: func(user *models.User, p Printer) TemplateVars {
+			return TemplateVars{"Target": Mention(user), "Admin": Mention(user)}
+		},
+	},
+}
+
+// LookupTemplate ищет описание шаблона по имени
+func LookupTemplate(name string) (TemplateInfo, bool) {
+	for _, info := range Templates {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return TemplateInfo{}, false
+}
+
+// Mention - упоминание пользователя для шаблона
+func Mention(user *models.User) Markdown {
+	return Markdown(GenerateMention(user))
+}
+
+// TopicLink - ссылка на топик супергруппы для шаблона
+func TopicLink(chatID int64, threadID int, title string) Markdown {
+	// У супергрупп ID начинается с -100, в ссылках его нет
+	readableChatID := strings.TrimPrefix(strconv.FormatInt(chatID, 10), "-100")
+	return Markdown(fmt.Sprintf("[%s](https://t.me/c/%s/%d)", EscapeMarkdown(title), readableChatID, threadID))
+}
+
+// TemplateRenderer собирает тексты Морти из шаблонов: сначала переопределение чата,
+// если его нет или оно сломалось - шаблон по умолчанию на языке чата из бинарника.
+type TemplateRenderer struct {
+	TemplateUseCase *usecase.TemplateUseCase
+	defaults        map[string]*template.Template // язык -> шаблоны по умолчанию
+	overrides       map[int64]map[string]*template.Template
+	mu              sync.Mutex
+	logger          *logger.Logger
+}
+
+func NewTemplateRenderer(logger *logger.Logger, templateUseCase *usecase.TemplateUseCase) *TemplateRenderer {
+	defaults := make(map[string]*template.Template)
+	files, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		lang := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		defaults[lang] = template.Must(template.New(lang).Option("missingkey=error").ParseFS(defaultTemplates, "templates/"+file.Name()))
+	}
+	return &TemplateRenderer{
+		TemplateUseCase: templateUseCase,
+		defaults:        defaults,
+		overrides:       make(map[int64]map[string]*template.Template),
+		logger:          logger,
+	}
+}
+
+// Render возвращает готовый MarkdownV2 для ParseModeMarkdown
+func (r *TemplateRenderer) Render(ctx context.Context, chatID int64, lang, name string, vars TemplateVars) string {
+	data := escapeTemplateVars(vars)
+	if tmpl, ok := r.chatOverrides(ctx, chatID)[name]; ok {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err == nil {
+			return sb.String()
+		} else {
+			r.logger.Error(ctx, "TemplateRenderer: override failed, using default", "template", name, "chat_id", chatID, "err", err)
+		}
+	}
+
+	var sb strings.Builder
+	if err := r.defaultsFor(lang).ExecuteTemplate(&sb, name, data); err != nil {
+		r.logger.Error(ctx, "TemplateRenderer: default template failed", "template", name, "err", err)
+		return EscapeMarkdown(name)
+	}
+	return sb.String()
+}
+
+// Source возвращает текст шаблона в чате и переопределён ли он
+func (r *TemplateRenderer) Source(ctx context.Context, chatID int64, lang, name string) (string, bool) {
+	overrides, err := r.TemplateUseCase.Overrides(ctx, chatID)
+	if err == nil {
+		for _, override := range overrides {
+			if override.Name == name {
+				return override.Body, true
+			}
+		}
+	}
+	if tmpl := r.defaultsFor(lang).Lookup(name); tmpl != nil {
+		return tmpl.Tree.Root.String(), false
+	}
+	return "", false
+}
+
+// Check разбирает шаблон и собирает его на примерных данных
+func (r *TemplateRenderer) Check(name, body string, user *models.User, p Printer) (string, error) {
+	info, ok := LookupTemplate(name)
+	if !ok {
+		return "", fmt.Errorf("unknown template: %s", name)
+	}
+	if len([]rune(body)) > MaxTemplateLen {
+		return "", fmt.Errorf("template is longer than %d characters", MaxTemplateLen)
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, escapeTemplateVars(info.Sample(user, p))); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Preview собирает шаблон чата на примерных данных
+func (r *TemplateRenderer) Preview(ctx context.Context, chatID int64, name string, user *models.User, p Printer) string {
+	info, _ := LookupTemplate(name)
+	return r.Render(ctx, chatID, p.Lang, name, info.Sample(user, p))
+}
+
+// defaultsFor - шаблоны по умолчанию на языке, а если их не перевели - на русском
+func (r *TemplateRenderer) defaultsFor(lang string) *template.Template {
+	if tmpl, ok := r.defaults[lang]; ok {
+		return tmpl
+	}
+	return r.defaults[DefaultLanguage]
+}
+
+// Invalidate забывает переопределения чата, они перечитаются при следующем сообщении
+func (r *TemplateRenderer) Invalidate(chatID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, chatID)
+}
+
+func (r *TemplateRenderer) chatOverrides(ctx context.Context, chatID int64) map[string]*template.Template {
+	r.mu.Lock()
+	cached, ok := r.overrides[chatID]
+	r.mu.Unlock()
+	if ok {
+		return cached
+	}
+
+	overrides, err := r.TemplateUseCase.Overrides(ctx, chatID)
+	if err != nil {
+		// Не кешируем: база вернётся - вернутся и переопределения
+		r.logger.Error(ctx, "TemplateRenderer: load overrides error", "chat_id", chatID, "err", err)
+		return nil
+	}
+	parsed := make(map[string]*template.Template, len(overrides))
+	for _, override := range overrides {
+		tmpl, err := template.New(override.Name).Option("missingkey=error").Parse(override.Body)
+		if err != nil {
+			r.logger.Error(ctx, "TemplateRenderer: parse override error", "template", override.Name, "chat_id", chatID, "err", err)
+			continue
+		}
+		parsed[override.Name] = tmpl
+	}
+
+	r.mu.Lock()
+	r.overrides[chatID] = parsed
+	r.mu.Unlock()
+	return parsed
+}
+
+// escapeTemplateVars экранирует строки, чтобы ник вроде "a_b" не ломал разметку
+func escapeTemplateVars(vars TemplateVars) map[string]any {
+	data := make(map[string]any, len(vars))
+	for key, value := range vars {
+		switch v := value.(type) {
+		case Markdown:
+			data[key] = string(v)
+		case string:
+			data[key] = EscapeMarkdown(v)
+		default:
+			data[key] = v
+		}
+	}
+	return data
+}
@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"context"
+	"morty-smith-34-c/internal/app/entity"
+	"morty-smith-34-c/internal/app/repository"
+	"morty-smith-34-c/internal/app/usecase"
+	"morty-smith-34-c/pkg/config"
+	"morty-smith-34-c/pkg/logger"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// fakeTemplateRepo - переопределения шаблонов по чатам
+type fakeTemplateRepo struct {
+	repository.MessageTemplateRepository
+	templates map[int64][]*entity.MessageTemplate
+}
+
+func (r *fakeTemplateRepo) GetByChatID(ctx context.Context, chatID int64) ([]*entity.MessageTemplate, error) {
+	return r.templates[chatID], nil
+}
+
+func newTestRenderer(t *testing.T, templates map[int64][]*entity.MessageTemplate) *TemplateRenderer {
+	t.Helper()
+	log := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"), &config.Config{})
+	return NewTemplateRenderer(log, usecase.NewTemplateUseCase(&fakeTemplateRepo{templates: templates}))
+}
+
+var testUser = &models.User{ID: 42, FirstName: "Morty_Smith"}
+
+func TestEscapeTemplateVars(t *testing.T) {
+	vars := TemplateVars{
+		"Nick":    "brie_yele",
+		"Reason":  "спам (опять).",
+		"User":    Mention(testUser),
+		"Minutes": 5,
+		"Forever": false,
+	}
+	want := map[string]any{
+		"Nick":    `brie\_yele`,
+		"Reason":  `спам \(опять\)\.`,
+		"User":    `[Morty\_Smith](tg://user?id=42)`,
+		"Minutes": 5,
+		"Forever": false,
+	}
+	if got := escapeTemplateVars(vars); !reflect.DeepEqual(got, want) {
+		t.Errorf("escapeTemplateVars = %#v, want %#v", got, want)
+	}
+}
+
+func TestRenderOverrideFallback(t *testing.T) {
+	const defaultText = "С возвращением"
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"working override", `Снова ты\, {{.User}}`, `Снова ты\, [Morty\_Smith](tg://user?id=42)`},
+		{"unknown variable", `Привет\, {{.Nick}}`, defaultText},
+		{"broken syntax", `Привет\, {{.User`, defaultText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRenderer(t, map[int64][]*entity.MessageTemplate{
+				1: {{ChatID: 1, Name: "welcome_back", Body: tt.body}},
+			})
+			got := r.Render(context.Background(), 1, "ru", "welcome_back", TemplateVars{"User": Mention(testUser)})
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Render = %q, want it to contain %q", got, tt.want)
+			}
+			// В другом чате переопределения нет
+			if other := r.Render(context.Background(), 2, "ru", "welcome_back", TemplateVars{"User": Mention(testUser)}); !strings.Contains(other, defaultText) {
+				t.Errorf("Render in another chat = %q, want the default", other)
+			}
+		})
+	}
+}
+
+func TestDefaultTemplatesRenderSamples(t *testing.T) {
+	r := newTestRenderer(t, nil)
+	localizer := NewLocalizer(nil)
+	files, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		lang := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		p := localizer.Printer(lang)
+		for _, info := range Templates {
+			t.Run(lang+"/"+info.Name, func(t *testing.T) {
+				var sb strings.Builder
+				if err := r.defaults[lang].ExecuteTemplate(&sb, info.Name, escapeTemplateVars(info.Sample(testUser, p))); err != nil {
+					t.Fatalf("%s: %v", file.Name(), err)
+				}
+				if strings.TrimSpace(sb.String()) == "" {
+					t.Errorf("%s renders empty text", file.Name())
+				}
+			})
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"ok", "nick_ok", `Ник {{.Nick}} принят\, {{.User}}`, `Ник brieyele принят\, [Morty\_Smith](tg://user?id=42)`, false},
+		{"unknown template", "goodbye", `Пока`, "", true},
+		{"unknown variable", "nick_ok", `{{.Target}}`, "", true},
+		{"broken syntax", "nick_ok", `{{if .Nick}}`, "", true},
+		{"too long", "nick_ok", strings.Repeat("а", MaxTemplateLen+1), "", true},
+	}
+	r := newTestRenderer(t, nil)
+	p := NewLocalizer(nil).Printer(DefaultLanguage)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Check(tt.tmpl, tt.body, testUser, p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Check = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
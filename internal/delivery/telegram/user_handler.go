@@ -2,12 +2,12 @@ package telegram
 
 import (
 	"context"
-	"fmt"
-	"strconv"
+	"errors"
 	"strings"
 	"sync"
 	"time"
 
+	"morty-smith-34-c/internal/app/entity"
 	"morty-smith-34-c/internal/app/usecase"
 	"morty-smith-34-c/internal/school"
 	"morty-smith-34-c/pkg/logger"
@@ -16,29 +16,42 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
+// degradedExtension - на сколько продлевается таймер новичка, пока School API лежит
+// или после того, как его ник не проверился из-за сбоя API
+const degradedExtension = 5 * time.Minute
+
 type UserHandler struct {
-	ChatUseCase *usecase.ChatUseCase
-	UserUseCase *usecase.UserUseCase
-	jwtService  school.JWTService
-	timers      map[int64]*time.Timer
-	messageIDs  map[int64]int
-	mu          sync.Mutex
-	logger      *logger.Logger
+	ChatUseCase       *usecase.ChatUseCase
+	UserUseCase       *usecase.UserUseCase
+	ChatMemberUseCase *usecase.ChatMemberUseCase
+	schoolClient      *school.Client
+	templates         *TemplateRenderer
+	i18n              *Localizer
+	timers            map[int64]*time.Timer
+	messageIDs        map[int64]int
+	deferred          map[int64]*models.Message // ники, которые ждут, пока School API оживёт
+	failedChecks      map[int64]bool            // ник не проверился из-за временного сбоя School API
+	mu                sync.Mutex
+	logger            *logger.Logger
 }
 
-func NewUserHandler(logger *logger.Logger, chatUseCase *usecase.ChatUseCase, userUseCase *usecase.UserUseCase, jwtService school.JWTService) *UserHandler {
+func NewUserHandler(logger *logger.Logger, chatUseCase *usecase.ChatUseCase, userUseCase *usecase.UserUseCase, chatMemberUseCase *usecase.ChatMemberUseCase, schoolClient *school.Client, templates *TemplateRenderer, localizer *Localizer) *UserHandler {
 	return &UserHandler{
-		ChatUseCase: chatUseCase,
-		UserUseCase: userUseCase,
-		jwtService:  jwtService,
-		timers:      make(map[int64]*time.Timer),
-		messageIDs:  make(map[int64]int),
-		logger:      logger,
+		ChatUseCase:       chatUseCase,
+		UserUseCase:       userUseCase,
+		ChatMemberUseCase: chatMemberUseCase,
+		schoolClient:      schoolClient,
+		templates:         templates,
+		i18n:              localizer,
+		timers:            make(map[int64]*time.Timer),
+		messageIDs:        make(map[int64]int),
+		deferred:          make(map[int64]*models.Message),
+		failedChecks:      make(map[int64]bool),
+		logger:            logger,
 	}
 }
 
 func (h *UserHandler) HandleNewMembers(ctx context.Context, b *bot.Bot, msg *models.Message, threadID int) {
-	readableChatID := strconv.FormatInt(msg.Chat.ID, 10)[4:]
 	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
@@ -53,17 +66,21 @@ func (h *UserHandler) HandleNewMembers(ctx context.Context, b *bot.Bot, msg *mod
 			)
 			continue
 		}
+		if err := h.ChatMemberUseCase.Joined(ctx, msg.Chat.ID, user.ID, exists); err != nil {
+			h.logger.Error(ctx, "HandleNewMembers: Failed to track member",
+				"user", UserForLogger(&user),
+				"chat", ChatForLogger(msg.Chat),
+				"err", err,
+			)
+		}
 
 		if exists {
 			// Приветствуем существующего пользователя
 			sendMessage, err := b.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID:          msg.Chat.ID,
 				MessageThreadID: msg.MessageThreadID,
-				Text: fmt.Sprintf(
-					"Эй\\, %s\\! С возвращением\\, рад увидеть знакомое лицо\\!",
-					GenerateMention(&user),
-				),
-				ParseMode: models.ParseModeMarkdown,
+				Text:            h.templates.Render(ctx, msg.Chat.ID, h.i18n.Lang(msg.Chat.ID, &user), "welcome_back", TemplateVars{"User": Mention(&user)}),
+				ParseMode:       models.ParseModeMarkdown,
 			})
 			if err != nil {
 				h.logger.Debug(ctx, "HandleNewMembers: Failed to send welcome back message",
@@ -84,10 +101,11 @@ func (h *UserHandler) HandleNewMembers(ctx context.Context, b *bot.Bot, msg *mod
 		sendMessage, err := b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:          msg.Chat.ID,
 			MessageThreadID: msg.MessageThreadID,
-			Text: fmt.Sprintf(
-				"Добро пожаловать\\, %s\\, у тебя есть **5 минут**\\, чтобы написать свой **школьный ник** в топик [ID](https://t.me/c/%s/%d)\\.",
-				GenerateMention(&user), readableChatID, threadID,
-			),
+			Text: h.templates.Render(ctx, msg.Chat.ID, h.i18n.Lang(msg.Chat.ID, &user), "welcome", TemplateVars{
+				"User":    Mention(&user),
+				"Minutes": 5,
+				"Topic":   TopicLink(msg.Chat.ID, threadID, "ID"),
+			}),
 			ParseMode: models.ParseModeMarkdown,
 		})
 		if err != nil {
@@ -107,7 +125,16 @@ func (h *UserHandler) HandleNewMembers(ctx context.Context, b *bot.Bot, msg *mod
 			h.mu.Lock()
 			defer h.mu.Unlock()
 
-			if _, exists := h.timers[user.ID]; exists {
+			if timer, exists := h.timers[user.ID]; exists {
+				if h.keepWaiting(user.ID) {
+					h.logger.Info(ctx, "HandleNewMembers: School API is unavailable, extend timer",
+						"user", UserForLogger(&user),
+						"chat", ChatForLogger(msg.Chat),
+					)
+					timer.Reset(degradedExtension)
+					return
+				}
+
 				b.DeleteMessage(ctx, &bot.DeleteMessageParams{
 					ChatID:    msg.Chat.ID,
 					MessageID: sendMessage.ID,
@@ -128,6 +155,8 @@ func (h *UserHandler) HandleNewMembers(ctx context.Context, b *bot.Bot, msg *mod
 						"chat", ChatForLogger(msg.Chat),
 						"err", err,
 					)
+				} else {
+					h.trackLeft(ctx, msg.Chat, &user, entity.MemberStatusBanned)
 				}
 
 				delete(h.timers, user.ID)
@@ -141,6 +170,18 @@ func (h *UserHandler) HandleNewMembers(ctx context.Context, b *bot.Bot, msg *mod
 	}
 }
 
+// keepWaiting решает, продлить ли таймер новичка вместо бана. Вызывается под h.mu.
+// Новичок не виноват, что School API лежит или сбоит: пока его ник ждёт проверки, таймер продлеваем.
+// После разового сбоя - один раз, чтобы он успел прислать ник снова.
+func (h *UserHandler) keepWaiting(userID int64) bool {
+	_, waiting := h.deferred[userID]
+	if waiting || h.failedChecks[userID] || !h.schoolClient.Available() {
+		delete(h.failedChecks, userID)
+		return true
+	}
+	return false
+}
+
 func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *models.Message) {
 	if _, exists := h.timers[msg.From.ID]; !exists {
 		h.logger.Debug(ctx, "HandleNickname: User does not have timer",
@@ -151,49 +192,48 @@ func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *model
 		return
 	}
 	msg.Text = strings.ToLower(msg.Text)
-	_, err := h.jwtService.CheckUser(ctx, msg.Text)
+	// Новичок ждёт ответа, его проверка идёт вперёд фоновых запросов
+	_, err := h.schoolClient.CheckUser(school.WithPriority(ctx, school.PriorityInteractive), msg.Text)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, school.ErrNotFound) {
 			h.logger.Info(ctx, "HandleNickname: User not found in School API",
 				"text", msg.Text,
 				"user", UserForLogger(msg.From),
 				"chat", ChatForLogger(msg.Chat),
 			)
-			sendMessage, _ := b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: msg.Chat.ID,
-				Text: fmt.Sprintf(
-					"Эй\\, %s\\! Не могу найти твой ник в Школе 21\\. Попробуй еще раз\\, без опечаток\\!",
-					GenerateMention(msg.From),
-				),
-				ReplyParameters: &models.ReplyParameters{
-					MessageID: msg.ID,
-				},
-				MessageThreadID: msg.MessageThreadID,
-				ParseMode:       models.ParseModeMarkdown,
-			})
-			b.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
-				ChatID:    msg.Chat.ID,
-				MessageID: msg.ID,
-				Reaction: []models.ReactionType{
-					{
-						Type:              models.ReactionTypeTypeEmoji,
-						ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: "👎"},
-					},
-				},
-			})
-			time.AfterFunc(time.Minute*2, func() {
-				b.DeleteMessage(ctx, &bot.DeleteMessageParams{
-					ChatID:    msg.Chat.ID,
-					MessageID: msg.ID,
-				})
-				b.DeleteMessage(ctx, &bot.DeleteMessageParams{
-					ChatID:    msg.Chat.ID,
-					MessageID: sendMessage.ID,
-				})
-			})
+			// API ответил, значит прошлый сбой больше не оправдание
+			h.mu.Lock()
+			delete(h.failedChecks, msg.From.ID)
+			h.mu.Unlock()
+			h.replyNickRetry(ctx, b, msg, "nick_not_found", "👎")
+			return
+		}
+		if errors.Is(err, school.ErrCircuitOpen) || (school.IsTemporary(err) && !h.schoolClient.Available()) {
+			// School API лежит: запоминаем ник и проверим его, когда API оживёт
+			h.logger.Warn(ctx, "HandleNickname: School API is down, defer check",
+				"text", msg.Text,
+				"user", UserForLogger(msg.From),
+				"chat", ChatForLogger(msg.Chat),
+				"err", err,
+			)
+			h.deferNickname(ctx, b, msg)
+			return
+		}
+		if school.IsTemporary(err) {
+			// Ник может быть правильным, просто School API сейчас не отвечает
+			h.logger.Warn(ctx, "HandleNickname: School API is temporarily unavailable",
+				"text", msg.Text,
+				"user", UserForLogger(msg.From),
+				"chat", ChatForLogger(msg.Chat),
+				"err", err,
+			)
+			h.mu.Lock()
+			h.failedChecks[msg.From.ID] = true
+			h.mu.Unlock()
+			h.replyNickRetry(ctx, b, msg, "nick_check_failed", "🤔")
 			return
 		}
-		if err.Error() == "not core program" || err.Error() == "profile blocked" {
+		if errors.Is(err, school.ErrNotCoreProgram) || errors.Is(err, school.ErrProfileBlocked) {
 			h.RemoveUserFromTimers(ctx, b, msg.Chat.ID, msg.From.ID)
 
 			_, err := b.BanChatMember(ctx, &bot.BanChatMemberParams{
@@ -211,6 +251,8 @@ func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *model
 					"user", UserForLogger(msg.From),
 					"chat", ChatForLogger(msg.Chat),
 				)
+			} else {
+				h.trackLeft(ctx, msg.Chat, msg.From, entity.MemberStatusBanned)
 			}
 
 			b.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
@@ -240,7 +282,7 @@ func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *model
 	h.RemoveUserFromTimers(ctx, b, msg.Chat.ID, msg.From.ID)
 
 	// Сохраняем ник в базе данных
-	err = h.UserUseCase.SaveNickname(ctx, msg.From.ID, msg.Text)
+	err = h.UserUseCase.SaveNickname(ctx, msg.From.ID, msg.Text, entity.NickSourceSelfVerified, nil)
 	if err != nil {
 		h.logger.Error(ctx, "HandleNickname: Error save to database",
 			"text", msg.Text,
@@ -250,6 +292,13 @@ func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *model
 		)
 		return
 	}
+	if err := h.ChatMemberUseCase.Verified(ctx, msg.Chat.ID, msg.From.ID); err != nil {
+		h.logger.Error(ctx, "HandleNickname: Failed to track member",
+			"user", UserForLogger(msg.From),
+			"chat", ChatForLogger(msg.Chat),
+			"err", err,
+		)
+	}
 
 	b.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
 		ChatID:    msg.Chat.ID,
@@ -269,10 +318,7 @@ func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *model
 	// Подтверждение для пользователя
 	sendMessage, _ := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: msg.Chat.ID,
-		Text: fmt.Sprintf(
-			"Круто\\, %s\\! Я проверил\\, и всё в порядке\\. Ты — наш человек\\! Соблюдай правила нашего сообщества\\!",
-			GenerateMention(msg.From),
-		),
+		Text:   h.templates.Render(ctx, msg.Chat.ID, h.i18n.Lang(msg.Chat.ID, msg.From), "nick_ok", TemplateVars{"User": Mention(msg.From), "Nick": msg.Text}),
 		ReplyParameters: &models.ReplyParameters{
 			MessageID: msg.ID,
 		},
@@ -289,10 +335,126 @@ func (h *UserHandler) HandleNickname(ctx context.Context, b *bot.Bot, msg *model
 	})
 }
 
+// replyNickRetry просит новичка прислать ник ещё раз: отвечает шаблоном, ставит реакцию
+// и через пару минут убирает и ник, и ответ, чтобы не засорять топик
+func (h *UserHandler) replyNickRetry(ctx context.Context, b *bot.Bot, msg *models.Message, templateName, emoji string) {
+	sendMessage, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   h.templates.Render(ctx, msg.Chat.ID, h.i18n.Lang(msg.Chat.ID, msg.From), templateName, TemplateVars{"User": Mention(msg.From), "Nick": msg.Text}),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		MessageThreadID: msg.MessageThreadID,
+		ParseMode:       models.ParseModeMarkdown,
+	})
+	b.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Reaction: []models.ReactionType{
+			{
+				Type:              models.ReactionTypeTypeEmoji,
+				ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: emoji},
+			},
+		},
+	})
+	time.AfterFunc(time.Minute*2, func() {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+		})
+		if sendMessage != nil {
+			b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+				ChatID:    msg.Chat.ID,
+				MessageID: sendMessage.ID,
+			})
+		}
+	})
+}
+
+// deferNickname откладывает проверку ника до восстановления School API и предупреждает новичка
+func (h *UserHandler) deferNickname(ctx context.Context, b *bot.Bot, msg *models.Message) {
+	// Кладём копию: ReplayDeferred по указателю отличает ник, отложенный заново, от того, что проверяет сам
+	deferred := *msg
+	h.mu.Lock()
+	h.deferred[msg.From.ID] = &deferred
+	h.mu.Unlock()
+
+	sendMessage, _ := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text:   h.templates.Render(ctx, msg.Chat.ID, h.i18n.Lang(msg.Chat.ID, msg.From), "nick_check_delayed", TemplateVars{"User": Mention(msg.From), "Nick": msg.Text}),
+		ReplyParameters: &models.ReplyParameters{
+			MessageID: msg.ID,
+		},
+		MessageThreadID: msg.MessageThreadID,
+		ParseMode:       models.ParseModeMarkdown,
+	})
+	b.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Reaction: []models.ReactionType{
+			{
+				Type:              models.ReactionTypeTypeEmoji,
+				ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: "👀"},
+			},
+		},
+	})
+	// Сам ник не удаляем, по нему будет проверка
+	if sendMessage != nil {
+		time.AfterFunc(time.Minute*2, func() {
+			b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+				ChatID:    msg.Chat.ID,
+				MessageID: sendMessage.ID,
+			})
+		})
+	}
+}
+
+// DeferredCount - сколько ников ждёт School API
+func (h *UserHandler) DeferredCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.deferred)
+}
+
+// ReplayDeferred проверяет отложенные ники. Ник остаётся в очереди, пока идёт его проверка,
+// чтобы таймер новичка, сработавший посреди неё, продлился, а не забанил его.
+// Если API снова ляжет по дороге, оставшиеся ники дождутся следующего раза.
+func (h *UserHandler) ReplayDeferred(ctx context.Context, b *bot.Bot) {
+	h.mu.Lock()
+	messages := make([]*models.Message, 0, len(h.deferred))
+	for _, msg := range h.deferred {
+		messages = append(messages, msg)
+	}
+	h.mu.Unlock()
+
+	for i, msg := range messages {
+		if !h.schoolClient.Available() {
+			h.logger.Warn(ctx, "ReplayDeferred: School API is down again", "left", len(messages)-i)
+			return
+		}
+		h.logger.Info(ctx, "ReplayDeferred: check deferred nickname",
+			"text", msg.Text,
+			"user", UserForLogger(msg.From),
+			"chat", ChatForLogger(msg.Chat),
+		)
+		h.HandleNickname(ctx, b, msg)
+
+		h.mu.Lock()
+		// Пока шла проверка, новичок мог прислать ник заново или его отложили снова
+		if h.deferred[msg.From.ID] == msg {
+			delete(h.deferred, msg.From.ID)
+		}
+		h.mu.Unlock()
+	}
+}
+
 func (h *UserHandler) RemoveUserFromTimers(ctx context.Context, b *bot.Bot, chatID int64, userID int64) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	delete(h.deferred, userID)
+	delete(h.failedChecks, userID)
+
 	if timer, exists := h.timers[userID]; exists {
 		timer.Stop()
 		delete(h.timers, userID)
@@ -306,3 +468,14 @@ func (h *UserHandler) RemoveUserFromTimers(ctx context.Context, b *bot.Bot, chat
 		delete(h.messageIDs, userID)
 	}
 }
+
+// trackLeft отмечает, что пользователь больше не в чате
+func (h *UserHandler) trackLeft(ctx context.Context, chat models.Chat, user *models.User, status string) {
+	if err := h.ChatMemberUseCase.Left(ctx, chat.ID, user.ID, status); err != nil {
+		h.logger.Error(ctx, "trackLeft: Failed to track member",
+			"user", UserForLogger(user),
+			"chat", ChatForLogger(chat),
+			"err", err,
+		)
+	}
+}
@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"context"
+	"io"
+	"morty-smith-34-c/internal/school"
+	"morty-smith-34-c/pkg/config"
+	"morty-smith-34-c/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// newBlockingSchool - School API, который держит проверку ника, пока тест не закроет release,
+// а потом отвечает 400: HandleNickname только пишет ошибку в лог и не трогает Telegram
+func newBlockingSchool(t *testing.T, log *logger.Logger) (*school.Client, chan<- struct{}, <-chan struct{}) {
+	t.Helper()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			io.WriteString(w, `{"access_token":"token","expires_in":3600}`)
+			return
+		}
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	queue := school.NewAPIQueue(100, time.Second, school.DefaultRetryPolicy, nil, log)
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Start(ctx)
+	t.Cleanup(cancel)
+
+	tokens := school.NewTokenSource("morty", "secret", srv.URL+"/token", queue, log)
+	breaker := school.NewBreaker(3, time.Minute, log)
+	return school.NewClient(srv.URL, tokens, queue, breaker, log), release, started
+}
+
+func TestReplayDeferredKeepsTimerWaiting(t *testing.T) {
+	log := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"), &config.Config{})
+	client, release, started := newBlockingSchool(t, log)
+	h := NewUserHandler(log, nil, nil, nil, client, nil, nil)
+
+	const newcomerID int64 = 7
+	msg := &models.Message{
+		ID:   1,
+		Text: "mortysmi",
+		From: &models.User{ID: newcomerID},
+		Chat: models.Chat{ID: -100},
+	}
+	h.timers[newcomerID] = time.AfterFunc(time.Hour, func() {})
+	t.Cleanup(func() { h.timers[newcomerID].Stop() })
+	h.deferred[newcomerID] = msg
+
+	done := make(chan struct{})
+	go func() {
+		h.ReplayDeferred(context.Background(), nil)
+		close(done)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay didn't check the nickname")
+	}
+	// Таймер новичка сработал, пока его ник проверяется
+	h.mu.Lock()
+	extend := h.keepWaiting(newcomerID)
+	h.mu.Unlock()
+	if !extend {
+		t.Error("timer fired mid-replay would ban the newcomer")
+	}
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay didn't finish")
+	}
+	if got := h.DeferredCount(); got != 0 {
+		t.Errorf("DeferredCount = %d after replay, want 0", got)
+	}
+}
+
+func TestReplayDeferredKeepsResentNickname(t *testing.T) {
+	log := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"), &config.Config{})
+	client, release, started := newBlockingSchool(t, log)
+	h := NewUserHandler(log, nil, nil, nil, client, nil, nil)
+
+	const newcomerID int64 = 7
+	from := &models.User{ID: newcomerID}
+	h.timers[newcomerID] = time.AfterFunc(time.Hour, func() {})
+	t.Cleanup(func() { h.timers[newcomerID].Stop() })
+	h.deferred[newcomerID] = &models.Message{ID: 1, Text: "mortysmi", From: from}
+
+	done := make(chan struct{})
+	go func() {
+		h.ReplayDeferred(context.Background(), nil)
+		close(done)
+	}()
+	<-started
+
+	// Пока шла проверка, новичок прислал другой ник, и его отложили
+	resent := &models.Message{ID: 2, Text: "rickyaso", From: from}
+	h.mu.Lock()
+	h.deferred[newcomerID] = resent
+	h.mu.Unlock()
+	close(release)
+	<-done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.deferred[newcomerID] != resent {
+		t.Error("replay dropped the nickname sent during the check")
+	}
+}
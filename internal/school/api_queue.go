@@ -11,43 +11,170 @@ import (
 	"time"
 )
 
+// Priority - полоса очереди. Запросы из разных полос делят общий лимит API
+// по весам полос, так что массовые запросы не задерживают проверку новичков.
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota // человек ждёт ответа прямо сейчас: проверка ника, /whois
+	PriorityBackground                  // фоновые задачи, по умолчанию
+	PriorityBulk                        // массовые перепроверки и импорт
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBackground:
+		return "background"
+	case PriorityBulk:
+		return "bulk"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// priorities - все полосы по порядку
+var priorities = []Priority{PriorityInteractive, PriorityBackground, PriorityBulk}
+
+type priorityKey struct{}
+
+// WithPriority задаёт полосу очереди для запросов к API с этим контекстом
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom возвращает полосу из контекста, по умолчанию PriorityBackground
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityBackground
+}
+
+// LaneConfig - настройки полосы
+type LaneConfig struct {
+	Weight int // доля лимита API, когда в очереди ждут несколько полос
+	Size   int // сколько запросов может ждать в полосе, дальше Submit отвечает ErrQueueFull
+}
+
+// DefaultLanes - полосы по умолчанию: когда заняты все полосы, из 10 запросов подряд
+// 6 достанутся новичкам, 3 фоновым задачам и 1 массовым
+var DefaultLanes = map[Priority]LaneConfig{
+	PriorityInteractive: {Weight: 6, Size: 100},
+	PriorityBackground:  {Weight: 3, Size: 300},
+	PriorityBulk:        {Weight: 1, Size: 1000},
+}
+
+// statsInterval - как часто очередь пишет статистику в лог
+const statsInterval = time.Minute
+
+// Result - результат запроса: либо Value, либо Err
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// LaneStats - статистика полосы с запуска
+type LaneStats struct {
+	Depth     int           // сколько запросов ждёт сейчас
+	Capacity  int           // сколько может ждать
+	Submitted int64         // принято в очередь
+	Rejected  int64         // не влезло в очередь
+	Processed int64         // отправлено в API, с повторами
+	Retried   int64         // вернулось в очередь на повтор
+	Cancelled int64         // отменено, пока ждали
+	TotalWait time.Duration // суммарное ожидание отправленных и отменённых
+	MaxWait   time.Duration // самое долгое ожидание
+}
+
+// AvgWait - среднее ожидание в полосе
+func (s LaneStats) AvgWait() time.Duration {
+	if n := s.Processed + s.Cancelled; n > 0 {
+		return s.TotalWait / time.Duration(n)
+	}
+	return 0
+}
+
 type ApiQueue interface {
 	Start(ctx context.Context)
-	AddRequest(ctx context.Context, method, endpoint string, headers map[string]string, body io.Reader) chan interface{}
+	// Submit ставит запрос в полосу PriorityFrom(req.Context()) и сразу возвращается.
+	// Запрос выполняется с req.Context(): если его отменить, пока запрос ждёт, запрос не отправится.
+	Submit(req *http.Request) (<-chan Result[*http.Response], error)
+	// Do ставит запрос в очередь и ждёт ответа или отмены req.Context()
+	Do(req *http.Request) (*http.Response, error)
+	// Stats возвращает статистику по полосам
+	Stats() map[Priority]LaneStats
+}
+
+// queuedRequest - запрос в очереди
+type queuedRequest struct {
+	req      *http.Request
+	enqueued time.Time
+	attempt  int // сколько раз уже отправляли
+	result   chan Result[*http.Response]
 }
 
-// Request - структура для запросов в очередь.
-type Request struct {
-	Method   string
-	Endpoint string
-	Headers  map[string]string
-	Body     io.Reader
-	Response chan interface{}
+// lane - полоса очереди
+type lane struct {
+	LaneConfig
+	requests chan queuedRequest
+	current  int // счётчик плавного взвешенного round robin
+	stats    LaneStats
 }
 
 // APIQueue - структура очереди запросов.
 type APIQueue struct {
 	httpClient   *http.Client
-	requestQueue chan Request
+	lanes        map[Priority]*lane
+	pending      chan struct{} // по метке на каждый запрос во всех полосах
 	maxRequests  int
 	interval     time.Duration
+	retry        RetryPolicy
 	lastExecuted time.Time
-	mu           sync.Mutex
+	pausedUntil  time.Time     // API ответил 429, до этого момента ничего не отправляем
+	done         chan struct{} // закрывается, когда очередь остановлена
+	stopped      bool          // очередь остановлена, новые запросы не принимаются
+	mu           sync.Mutex    // защищает stats, lastExecuted, pausedUntil и stopped, под ним запросы кладутся в полосы
 	logger       *logger.Logger
 }
 
-// NewAPIQueue создает новый экземпляр APIQueue.
-func NewAPIQueue(maxRequests int, interval time.Duration, httpClient *http.Client, logger *logger.Logger) ApiQueue {
+// NewAPIQueue создает новый экземпляр APIQueue с полосами DefaultLanes.
+func NewAPIQueue(maxRequests int, interval time.Duration, retry RetryPolicy, httpClient *http.Client, logger *logger.Logger) ApiQueue {
+	return NewAPIQueueWithLanes(maxRequests, interval, retry, httpClient, DefaultLanes, logger)
+}
+
+// NewAPIQueueWithLanes создает очередь со своими настройками полос.
+// Полоса без настроек берёт их из DefaultLanes.
+func NewAPIQueueWithLanes(maxRequests int, interval time.Duration, retry RetryPolicy, httpClient *http.Client, lanes map[Priority]LaneConfig, logger *logger.Logger) ApiQueue {
 	if httpClient == nil {
 		httpClient = &http.Client{}
 	}
-	return &APIQueue{
-		httpClient:   httpClient,
-		requestQueue: make(chan Request, 500),
-		maxRequests:  maxRequests,
-		interval:     interval,
-		logger:       logger,
+	retry.MaxAttempts = max(retry.MaxAttempts, 1)
+	q := &APIQueue{
+		httpClient:  httpClient,
+		lanes:       make(map[Priority]*lane, len(priorities)),
+		maxRequests: maxRequests,
+		interval:    interval,
+		retry:       retry,
+		done:        make(chan struct{}),
+		logger:      logger,
+	}
+	total := 0
+	for _, p := range priorities {
+		cfg, ok := lanes[p]
+		if !ok {
+			cfg = DefaultLanes[p]
+		}
+		cfg.Weight = max(cfg.Weight, 1)
+		q.lanes[p] = &lane{
+			LaneConfig: cfg,
+			requests:   make(chan queuedRequest, cfg.Size),
+			stats:      LaneStats{Capacity: cfg.Size},
+		}
+		total += cfg.Size
 	}
+	q.pending = make(chan struct{}, total)
+	return q
 }
 
 // Start запускает обработку очереди запросов.
@@ -56,77 +183,298 @@ func (q *APIQueue) Start(ctx context.Context) {
 		q.logger.Info(ctx, "Starting API queue")
 		limiter := time.NewTicker(q.interval / time.Duration(q.maxRequests))
 		defer limiter.Stop()
+		statsTicker := time.NewTicker(statsInterval)
+		defer statsTicker.Stop()
 
+		// ready - отменённый запрос не потратил разрешение лимитера, следующий идёт сразу
+		ready := false
 		for {
 			select {
-			case req := <-q.requestQueue:
-				<-limiter.C
-				q.mu.Lock()
-				q.lastExecuted = time.Now()
-				q.mu.Unlock()
-				q.logger.Debug(ctx, fmt.Sprintf("Processing request to %s", req.Endpoint))
-				go q.executeRequest(ctx, req)
+			case <-q.pending:
+			case <-statsTicker.C:
+				q.logStats(ctx)
+				continue
 			case <-ctx.Done():
-				q.logger.Info(ctx, "Stopping API queue")
+				q.stop(ctx)
 				return
 			}
+			if pause := q.pause(); pause > 0 {
+				q.logger.Debug(ctx, fmt.Sprintf("API queue is paused for %s", pause))
+				select {
+				case <-time.After(pause):
+				case <-ctx.Done():
+					q.stop(ctx)
+					return
+				}
+			}
+			if !ready {
+				select {
+				case <-limiter.C:
+				case <-ctx.Done():
+					q.stop(ctx)
+					return
+				}
+			}
+			ready = true
+
+			// Запрос выбирается уже после лимитера, чтобы срочный запрос, пришедший
+			// за это время, не стоял за массовым
+			p, item := q.next()
+			if err := item.req.Context().Err(); err != nil {
+				q.record(p, item, func(s *LaneStats) { s.Cancelled++ })
+				item.result <- Result[*http.Response]{Err: err}
+				continue
+			}
+			ready = false
+			q.record(p, item, func(s *LaneStats) { s.Processed++ })
+			q.logger.Debug(ctx, fmt.Sprintf("Processing %s request to %s", p, item.req.URL))
+			go q.executeRequest(item)
 		}
 	}()
 }
 
-// executeRequest выполняет запрос из очереди.
-func (q *APIQueue) executeRequest(ctx context.Context, req Request) {
-	q.logger.Debug(ctx, fmt.Sprintf("Executing request: %s %s", req.Method, req.Endpoint))
+// next выбирает запрос плавным взвешенным round robin среди непустых полос.
+// Вызывается после метки из pending, а Submit кладёт метку после запроса,
+// так что хотя бы одна полоса не пуста.
+func (q *APIQueue) next() (Priority, queuedRequest) {
+	var best *lane
+	var bestPriority Priority
+	total := 0
+	for _, p := range priorities {
+		l := q.lanes[p]
+		if len(l.requests) == 0 {
+			continue
+		}
+		l.current += l.Weight
+		total += l.Weight
+		if best == nil || l.current > best.current {
+			best, bestPriority = l, p
+		}
+	}
+	best.current -= total
+	return bestPriority, <-best.requests
+}
 
-	// Создаем HTTP-запрос
-	httpReq, err := http.NewRequest(req.Method, req.Endpoint, req.Body)
-	if err != nil {
-		q.logger.Error(ctx, fmt.Sprintf("Failed to create request: %v", err))
-		req.Response <- fmt.Errorf("failed to create request: %w", err)
-		return
+// record обновляет статистику полосы по запросу, который покинул очередь
+func (q *APIQueue) record(p Priority, item queuedRequest, update func(s *LaneStats)) {
+	wait := time.Since(item.enqueued)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := &q.lanes[p].stats
+	update(s)
+	s.TotalWait += wait
+	s.MaxWait = max(s.MaxWait, wait)
+	q.lastExecuted = time.Now()
+}
+
+// logStats пишет в лог статистику полос, через которые шли запросы
+func (q *APIQueue) logStats(ctx context.Context) {
+	stats := q.Stats()
+	for _, p := range priorities {
+		s := stats[p]
+		if s.Submitted == 0 && s.Rejected == 0 {
+			continue
+		}
+		q.logger.Info(ctx, "API queue lane stats",
+			"lane", p.String(),
+			"depth", s.Depth,
+			"capacity", s.Capacity,
+			"submitted", s.Submitted,
+			"rejected", s.Rejected,
+			"processed", s.Processed,
+			"retried", s.Retried,
+			"cancelled", s.Cancelled,
+			"avg_wait", s.AvgWait().String(),
+			"max_wait", s.MaxWait.String(),
+		)
 	}
+}
 
-	// Добавляем заголовки
-	for key, value := range req.Headers {
-		httpReq.Header.Add(key, value)
+// Stats возвращает статистику по полосам
+func (q *APIQueue) Stats() map[Priority]LaneStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := make(map[Priority]LaneStats, len(q.lanes))
+	for p, l := range q.lanes {
+		s := l.stats
+		s.Depth = len(l.requests)
+		stats[p] = s
 	}
+	return stats
+}
+
+// stop отвечает всем ждущим, что очередь остановлена
+func (q *APIQueue) stop(ctx context.Context) {
+	q.logger.Info(ctx, "Stopping API queue")
+	// После этого enqueue уже ничего не положит в полосы, и разбор ниже никого не пропустит
+	q.mu.Lock()
+	q.stopped = true
+	close(q.done)
+	q.mu.Unlock()
+	for _, l := range q.lanes {
+		for len(l.requests) > 0 {
+			item := <-l.requests
+			item.result <- Result[*http.Response]{Err: ErrQueueStopped}
+		}
+	}
+}
+
+// executeRequest выполняет запрос из очереди. Упавший по сети или получивший 5xx
+// идемпотентный запрос и любой запрос с ответом 429 возвращаются в очередь по RetryPolicy.
+func (q *APIQueue) executeRequest(item queuedRequest) {
+	ctx := item.req.Context()
+	item.attempt++
+	q.logger.Debug(ctx, fmt.Sprintf("Executing request: %s %s, attempt %d", item.req.Method, item.req.URL, item.attempt))
 
-	// Выполняем запрос
-	resp, err := q.httpClient.Do(httpReq)
+	resp, err := q.httpClient.Do(item.req)
+	if err == nil {
+		// Читаем тело ответа в память, чтобы соединение освободилось сразу
+		var body []byte
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			item.result <- Result[*http.Response]{Err: ctx.Err()}
+			return
+		}
+		if q.retryLater(item, q.retry.backoff(item.attempt), idempotent(item.req), err.Error()) {
+			return
+		}
 		q.logger.Error(ctx, fmt.Sprintf("Failed to execute request: %v", err))
-		req.Response <- fmt.Errorf("failed to execute request: %w", err)
+		item.result <- Result[*http.Response]{Err: fmt.Errorf("%w: %w", ErrTemporarilyUnavailable, err)}
 		return
 	}
 
-	// Читаем тело ответа в память
-	body, err := io.ReadAll(resp.Body)
-	resp.Body.Close() // Закрываем оригинальное тело, так как оно уже прочитано
-	if err != nil {
-		q.logger.Error(ctx, fmt.Sprintf("Failed to read response body: %v", err))
-		req.Response <- fmt.Errorf("failed to read response body: %w", err)
-		return
+	q.logger.Debug(ctx, fmt.Sprintf("Request to %s completed with status %d", item.req.URL, resp.StatusCode))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		delay := retryAfter(resp, time.Now())
+		if delay == 0 {
+			delay = q.retry.backoff(item.attempt)
+		}
+		// Лимит общий, так что притормаживаем всю очередь, а не только этот запрос
+		q.slowDown(ctx, delay)
+		if q.retryLater(item, delay, replayable(item.req), resp.Status) {
+			return
+		}
+	case temporaryStatus(resp.StatusCode):
+		if q.retryLater(item, q.retry.backoff(item.attempt), idempotent(item.req), resp.Status) {
+			return
+		}
 	}
+	item.result <- Result[*http.Response]{Value: resp}
+}
 
-	// Создаем новое тело из прочитанного контента
-	resp.Body = io.NopCloser(bytes.NewBuffer(body))
+// retryLater возвращает запрос в очередь через delay, если ещё есть попытки
+// и ответ успеет прийти до дедлайна запроса
+func (q *APIQueue) retryLater(item queuedRequest, delay time.Duration, allowed bool, reason string) bool {
+	ctx := item.req.Context()
+	if !allowed || item.attempt >= q.retry.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return false
+	}
+	// Тело уже прочитано первой попыткой, повтору нужна своя копия запроса
+	retry := item.req.Clone(ctx)
+	if item.req.GetBody != nil {
+		body, err := item.req.GetBody()
+		if err != nil {
+			return false
+		}
+		retry.Body = body
+	}
+	item.req = retry
+
+	q.logger.Warn(ctx, fmt.Sprintf("Retrying %s %s in %s after %s, attempt %d of %d", item.req.Method, item.req.URL, delay, reason, item.attempt+1, q.retry.MaxAttempts))
+	time.AfterFunc(delay, func() {
+		item.enqueued = time.Now()
+		if err := q.enqueue(item, true); err != nil {
+			item.result <- Result[*http.Response]{Err: err}
+		}
+	})
+	return true
+}
+
+// slowDown приостанавливает отправку запросов на delay
+func (q *APIQueue) slowDown(ctx context.Context, delay time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	until := time.Now().Add(delay)
+	if until.After(q.pausedUntil) {
+		q.pausedUntil = until
+		q.logger.Warn(ctx, fmt.Sprintf("School API rate limit hit, pausing queue for %s", delay))
+	}
+}
+
+// pause - сколько ещё ждать после 429
+func (q *APIQueue) pause() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return time.Until(q.pausedUntil)
+}
 
-	q.logger.Debug(ctx, fmt.Sprintf("Request to %s completed with status %d", req.Endpoint, resp.StatusCode))
+// Submit добавляет запрос в его полосу, не дожидаясь места в ней.
+func (q *APIQueue) Submit(req *http.Request) (<-chan Result[*http.Response], error) {
+	// Буфер на один ответ: исполнитель не зависнет, если вызывающий уже ушёл
+	result := make(chan Result[*http.Response], 1)
+	if err := q.enqueue(queuedRequest{req: req, enqueued: time.Now(), result: result}, false); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// enqueue кладёт запрос в полосу PriorityFrom(req.Context()), retry - запрос вернулся на повтор
+func (q *APIQueue) enqueue(item queuedRequest, retry bool) error {
+	req := item.req
+	ctx := req.Context()
+	p := PriorityFrom(ctx)
+	l, ok := q.lanes[p]
+	if !ok {
+		p, l = PriorityBackground, q.lanes[PriorityBackground]
+	}
 
-	// Возвращаем ответ через канал
-	req.Response <- resp
+	// Проверка остановки и запись в полосу под одним замком, иначе stop может
+	// разобрать полосы раньше, чем сюда попадёт запрос, и он повиснет навсегда
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return ErrQueueStopped
+	}
+	select {
+	case l.requests <- item:
+	default:
+		l.stats.Rejected++
+		q.mu.Unlock()
+		q.logger.Warn(ctx, fmt.Sprintf("API queue lane %s is full, dropping request: %s %s", p, req.Method, req.URL))
+		return ErrQueueFull
+	}
+	if retry {
+		l.stats.Retried++
+	} else {
+		l.stats.Submitted++
+	}
+	// В pending места на все полосы сразу, метка не заблокирует
+	q.pending <- struct{}{}
+	q.mu.Unlock()
+	q.logger.Debug(ctx, fmt.Sprintf("Adding %s request to queue: %s %s", p, req.Method, req.URL))
+	return nil
 }
 
-// AddRequest добавляет запрос в очередь.
-func (q *APIQueue) AddRequest(ctx context.Context, method, endpoint string, headers map[string]string, body io.Reader) chan interface{} {
-	q.logger.Debug(ctx, fmt.Sprintf("Adding request to queue: %s %s", method, endpoint))
-	responseChan := make(chan interface{})
-	q.requestQueue <- Request{
-		Method:   method,
-		Endpoint: endpoint,
-		Headers:  headers,
-		Body:     body,
-		Response: responseChan,
+// Do добавляет запрос в очередь и ждёт ответа.
+func (q *APIQueue) Do(req *http.Request) (*http.Response, error) {
+	result, err := q.Submit(req)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case res := <-result:
+		return res.Value, res.Err
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-q.done:
+		return nil, ErrQueueStopped
 	}
-	return responseChan
 }
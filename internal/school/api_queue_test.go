@@ -0,0 +1,266 @@
+package school
+
+import (
+	"context"
+	"errors"
+	"io"
+	"morty-smith-34-c/pkg/config"
+	"morty-smith-34-c/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	return logger.NewLogger(filepath.Join(t.TempDir(), "test.log"), &config.Config{})
+}
+
+// newTestServer отвечает "ok" и считает запросы
+func newTestServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func startQueue(t *testing.T, q ApiQueue) context.CancelFunc {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+	t.Cleanup(cancel)
+	return cancel
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestAPIQueueSubmitResult(t *testing.T) {
+	srv, hits := newTestServer(t)
+	q := NewAPIQueue(100, time.Second, DefaultRetryPolicy, nil, newTestLogger(t))
+	startQueue(t, q)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	result, err := q.Submit(req)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			t.Fatalf("Result.Err = %v", res.Err)
+		}
+		if res.Value.StatusCode != http.StatusOK {
+			t.Errorf("status = %d, want 200", res.Value.StatusCode)
+		}
+		if body := readBody(t, res.Value); body != "ok" {
+			t.Errorf("body = %q, want ok", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result")
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("server hits = %d, want 1", got)
+	}
+}
+
+func TestAPIQueueDo(t *testing.T) {
+	srv, _ := newTestServer(t)
+	q := NewAPIQueue(100, time.Second, DefaultRetryPolicy, nil, newTestLogger(t))
+	startQueue(t, q)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := q.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if body := readBody(t, resp); body != "ok" {
+		t.Errorf("body = %q, want ok", body)
+	}
+}
+
+func TestAPIQueueCancelWhileQueued(t *testing.T) {
+	srv, hits := newTestServer(t)
+	// Очередь не запущена: запрос гарантированно ждёт, пока его отменяют
+	q := NewAPIQueue(1, time.Second, DefaultRetryPolicy, nil, newTestLogger(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	result, err := q.Submit(req)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	cancel()
+	startQueue(t, q)
+
+	select {
+	case res := <-result:
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("Result.Err = %v, want context.Canceled", res.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result")
+	}
+	if got := atomic.LoadInt32(hits); got != 0 {
+		t.Errorf("cancelled request reached the server %d times", got)
+	}
+	if s := q.Stats()[PriorityBackground]; s.Cancelled != 1 {
+		t.Errorf("Cancelled = %d, want 1", s.Cancelled)
+	}
+}
+
+func TestAPIQueueDoReturnsOnCancel(t *testing.T) {
+	srv, _ := newTestServer(t)
+	q := NewAPIQueue(1, time.Second, DefaultRetryPolicy, nil, newTestLogger(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := q.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestAPIQueueFull(t *testing.T) {
+	srv, _ := newTestServer(t)
+	q := NewAPIQueueWithLanes(1, time.Second, DefaultRetryPolicy, nil, map[Priority]LaneConfig{
+		PriorityBackground: {Weight: 1, Size: 2},
+	}, newTestLogger(t))
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if _, err := q.Submit(req); err != nil {
+			t.Fatalf("Submit %d: %v", i, err)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if _, err := q.Submit(req); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit over capacity = %v, want ErrQueueFull", err)
+	}
+	if !IsTemporary(ErrQueueFull) {
+		t.Error("ErrQueueFull must be temporary")
+	}
+
+	// Другая полоса не заполнена
+	interactive, _ := http.NewRequestWithContext(WithPriority(context.Background(), PriorityInteractive), http.MethodGet, srv.URL, nil)
+	if _, err := q.Submit(interactive); err != nil {
+		t.Errorf("Submit to another lane: %v", err)
+	}
+	if s := q.Stats()[PriorityBackground]; s.Rejected != 1 || s.Depth != 2 {
+		t.Errorf("stats = %+v, want Rejected 1 and Depth 2", s)
+	}
+}
+
+func TestAPIQueueStopped(t *testing.T) {
+	srv, _ := newTestServer(t)
+	q := NewAPIQueue(1, time.Hour, DefaultRetryPolicy, nil, newTestLogger(t))
+
+	// Запрос, который так и не дождался лимитера
+	waiting, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	result, err := q.Submit(waiting)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	stop := startQueue(t, q)
+	stop()
+
+	select {
+	case res := <-result:
+		if !errors.Is(res.Err, ErrQueueStopped) {
+			t.Errorf("waiting Result.Err = %v, want ErrQueueStopped", res.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result for waiting request")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if _, err := q.Submit(req); !errors.Is(err, ErrQueueStopped) {
+		t.Errorf("Submit after stop = %v, want ErrQueueStopped", err)
+	}
+	if _, err := q.Do(req); !errors.Is(err, ErrQueueStopped) {
+		t.Errorf("Do after stop = %v, want ErrQueueStopped", err)
+	}
+}
+
+func TestAPIQueueSubmitRacingStop(t *testing.T) {
+	srv, _ := newTestServer(t)
+	// Лимитер не пропустит ни одного запроса, все они ждут в полосах
+	q := NewAPIQueue(1, time.Hour, DefaultRetryPolicy, nil, newTestLogger(t))
+	stop := startQueue(t, q)
+
+	var wg sync.WaitGroup
+	results := make(chan (<-chan Result[*http.Response]), 64)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if result, err := q.Submit(req); err == nil {
+				results <- result
+			}
+		}()
+	}
+	stop()
+	wg.Wait()
+	close(results)
+
+	// Каждый принятый запрос должен получить ответ, а не повиснуть в полосе после остановки
+	for result := range results {
+		select {
+		case <-result:
+		case <-time.After(5 * time.Second):
+			t.Fatal("accepted request got no result after stop")
+		}
+	}
+}
+
+func TestQueueTransportRoundTrip(t *testing.T) {
+	var gotHeader, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Morty")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "created")
+	}))
+	defer srv.Close()
+
+	q := NewAPIQueue(100, time.Second, DefaultRetryPolicy, nil, newTestLogger(t))
+	startQueue(t, q)
+	client := &http.Client{Transport: &QueueTransport{Queue: q}}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("wubba lubba"))
+	req.Header.Set("X-Morty", "aw jeez")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("client.Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want 201", resp.StatusCode)
+	}
+	if resp.Request == nil || resp.Request.URL.String() != srv.URL {
+		t.Errorf("resp.Request = %v, want request to %s", resp.Request, srv.URL)
+	}
+	if body := readBody(t, resp); body != "created" {
+		t.Errorf("body = %q, want created", body)
+	}
+	if gotHeader != "aw jeez" || gotBody != "wubba lubba" {
+		t.Errorf("server got header %q body %q", gotHeader, gotBody)
+	}
+}
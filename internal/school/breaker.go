@@ -0,0 +1,166 @@
+package school
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"morty-smith-34-c/pkg/logger"
+	"sync"
+	"time"
+)
+
+// BreakerState - состояние предохранителя
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // API работает, запросы идут как обычно
+	BreakerOpen                         // API лежит, запросы сразу получают ErrCircuitOpen
+	BreakerHalfOpen                     // пауза прошла, один пробный запрос проверяет, ожил ли API
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+// Breaker - предохранитель School API. После threshold временных ошибок подряд
+// (сеть, 5xx, недоступный Keycloak) он размыкается, и запросы не ждут очереди и повторов,
+// а сразу получают ErrCircuitOpen. Через cooldown один запрос проходит пробным:
+// ответил API - предохранитель замыкается, нет - снова ждём cooldown.
+//
+// Каждая смена состояния начинает новое поколение. Allow выдаёт поколение запроса,
+// и Record не верит запросам из прошлых поколений: запрос, отправленный ещё до обрыва,
+// не должен ни замкнуть предохранитель, ни разомкнуть его заново.
+type Breaker struct {
+	threshold  int
+	cooldown   time.Duration
+	state      BreakerState
+	generation uint64
+	failures   int       // временных ошибок подряд
+	openedAt   time.Time // когда разомкнулся последний раз
+	probing    bool      // пробный запрос уже ушёл
+	onChange   []func(from, to BreakerState)
+	mu         sync.Mutex
+	logger     *logger.Logger
+}
+
+func NewBreaker(threshold int, cooldown time.Duration, logger *logger.Logger) *Breaker {
+	return &Breaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		logger:    logger,
+	}
+}
+
+// OnChange подписывает fn на смену состояния. fn вызывается синхронно,
+// долгую работу она должна уносить в свою горутину.
+func (b *Breaker) OnChange(fn func(from, to BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = append(b.onChange, fn)
+}
+
+// State возвращает текущее состояние
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow решает, можно ли отправить запрос, и возвращает его поколение.
+// Каждый пропущенный запрос должен закончиться Record с этим поколением.
+func (b *Breaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			b.mu.Unlock()
+			return 0, ErrCircuitOpen
+		}
+		// Пауза прошла: этот запрос - пробный
+		b.probing = true
+		generation := b.setState(BreakerHalfOpen)
+		return generation, nil
+	case BreakerHalfOpen:
+		if b.probing {
+			b.mu.Unlock()
+			return 0, ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	return generation, nil
+}
+
+// Record учитывает результат запроса поколения generation.
+// В замкнутом состоянии считаются временные ошибки подряд, ответ API сбрасывает счёт.
+// В полуоткрытом решает только пробный запрос: успех или 404 - API жив, предохранитель
+// замыкается, любой другой ответ - снова ждём cooldown. Отмена запроса вызывающим
+// ничего не говорит о API, она только освобождает место для следующей пробы.
+func (b *Breaker) Record(generation uint64, err error) {
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	cancelled := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+
+	switch b.state {
+	case BreakerClosed:
+		switch {
+		case cancelled || errors.Is(err, ErrRateLimited):
+		case errors.Is(err, ErrTemporarilyUnavailable):
+			b.failures++
+			if b.failures >= b.threshold {
+				b.open(err)
+				return
+			}
+		default:
+			b.failures = 0
+		}
+	case BreakerHalfOpen:
+		b.probing = false
+		switch {
+		case cancelled:
+		case err == nil || errors.Is(err, ErrNotFound):
+			b.failures = 0
+			b.logger.Info(context.Background(), "School API is available again, closing circuit breaker")
+			b.setState(BreakerClosed)
+			return
+		default:
+			b.open(err)
+			return
+		}
+	}
+	b.mu.Unlock()
+}
+
+// open размыкает предохранитель, вызывается под mu и отпускает его
+func (b *Breaker) open(err error) {
+	b.openedAt = time.Now()
+	b.logger.Warn(context.Background(), fmt.Sprintf("School API is unavailable, opening circuit breaker for %s: %v", b.cooldown, err))
+	b.setState(BreakerOpen)
+}
+
+// setState меняет состояние и начинает новое поколение, отпускает mu
+// и оповещает подписчиков. Возвращает новое поколение.
+func (b *Breaker) setState(to BreakerState) uint64 {
+	from := b.state
+	b.state = to
+	b.generation++
+	generation := b.generation
+	listeners := b.onChange
+	b.mu.Unlock()
+	for _, fn := range listeners {
+		fn(from, to)
+	}
+	return generation
+}
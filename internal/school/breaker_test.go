@@ -0,0 +1,126 @@
+package school
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errDown = &StatusError{StatusCode: 503}
+
+func allow(t *testing.T, b *Breaker) uint64 {
+	t.Helper()
+	generation, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	return generation
+}
+
+// tripped - разомкнутый предохранитель с коротким cooldown
+func tripped(t *testing.T) *Breaker {
+	t.Helper()
+	b := NewBreaker(2, 20*time.Millisecond, newTestLogger(t))
+	for i := 0; i < 2; i++ {
+		b.Record(allow(t, b), errDown)
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	return b
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := NewBreaker(2, time.Minute, newTestLogger(t))
+	b.Record(allow(t, b), errDown)
+	b.Record(allow(t, b), ErrNotFound) // API ответил, счёт сбрасывается
+	b.Record(allow(t, b), errDown)
+	if b.State() != BreakerClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+	b.Record(allow(t, b), errDown)
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) || !IsTemporary(err) {
+		t.Errorf("Allow while open = %v, want temporary ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerIgnoresStaleResults(t *testing.T) {
+	b := NewBreaker(2, time.Minute, newTestLogger(t))
+	inFlight := allow(t, b)
+	b.Record(allow(t, b), errDown)
+	b.Record(allow(t, b), errDown)
+
+	// Запрос, отправленный до обрыва, не замыкает предохранитель
+	for _, err := range []error{nil, ErrNotFound, ErrRateLimited} {
+		b.Record(inFlight, err)
+		if b.State() != BreakerOpen {
+			t.Fatalf("stale %v closed the breaker", err)
+		}
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		state BreakerState
+	}{
+		{"success closes", nil, BreakerClosed},
+		{"not found closes", ErrNotFound, BreakerClosed},
+		{"temporary reopens", errDown, BreakerOpen},
+		{"rate limited reopens", &StatusError{StatusCode: 429}, BreakerOpen},
+		{"cancel keeps half-open", context.Canceled, BreakerHalfOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tripped(t)
+			time.Sleep(30 * time.Millisecond)
+			probe := allow(t, b)
+			if b.State() != BreakerHalfOpen {
+				t.Fatalf("state = %s, want half-open", b.State())
+			}
+			if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("second probe allowed: %v", err)
+			}
+			b.Record(probe, tt.err)
+			if b.State() != tt.state {
+				t.Errorf("state = %s, want %s", b.State(), tt.state)
+			}
+		})
+	}
+}
+
+func TestBreakerCancelledProbeFreesSlot(t *testing.T) {
+	b := tripped(t)
+	time.Sleep(30 * time.Millisecond)
+	b.Record(allow(t, b), context.Canceled)
+	b.Record(allow(t, b), nil)
+	if b.State() != BreakerClosed {
+		t.Errorf("state = %s, want closed", b.State())
+	}
+}
+
+func TestBreakerOnChange(t *testing.T) {
+	b := NewBreaker(1, 20*time.Millisecond, newTestLogger(t))
+	var changes []BreakerState
+	b.OnChange(func(from, to BreakerState) { changes = append(changes, to) })
+
+	b.Record(allow(t, b), errDown)
+	time.Sleep(30 * time.Millisecond)
+	b.Record(allow(t, b), nil)
+
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes = %v, want %v", changes, want)
+			break
+		}
+	}
+}
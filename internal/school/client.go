@@ -0,0 +1,297 @@
+package school
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"morty-smith-34-c/pkg/logger"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaxPageLimit - больше записей за раз API не отдаёт
+const MaxPageLimit = 1000
+
+// ListOptions - страница списка. Limit 0 - сколько отдаёт API по умолчанию.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+func (o ListOptions) values() url.Values {
+	query := url.Values{}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(min(o.Limit, MaxPageLimit)))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return query
+}
+
+// All выкачивает список целиком, страницами по MaxPageLimit:
+//
+//	projects, err := school.All(ctx, func(ctx context.Context, opts school.ListOptions) ([]school.ParticipantProject, error) {
+//		return client.ParticipantProjects(ctx, login, "", opts)
+//	})
+func All[T any](ctx context.Context, list func(ctx context.Context, opts ListOptions) ([]T, error)) ([]T, error) {
+	var all []T
+	opts := ListOptions{Limit: MaxPageLimit}
+	for {
+		page, err := list(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		// Неполная страница - последняя
+		if len(page) < opts.Limit {
+			return all, nil
+		}
+		opts.Offset += len(page)
+	}
+}
+
+// Client - клиент School 21 Platform API. Токены подставляет BearerTransport,
+// все запросы идут через APIQueue, чтобы не превышать лимит API,
+// а пока API лежит, Breaker отвечает за него ErrCircuitOpen.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	breaker *Breaker
+	logger  *logger.Logger
+}
+
+// NewClient создаёт клиент, baseURL - адрес API вроде https://edu-api.21-school.ru/services/21-school/api/v1
+func NewClient(baseURL string, tokens *TokenSource, apiQueue ApiQueue, breaker *Breaker, logger *logger.Logger) *Client {
+	return &Client{
+		baseURL: baseURL,
+		http: &http.Client{
+			Transport: &BearerTransport{Source: tokens, Base: &QueueTransport{Queue: apiQueue}},
+		},
+		breaker: breaker,
+		logger:  logger,
+	}
+}
+
+// Available - предохранитель замкнут, API считается рабочим
+func (c *Client) Available() bool {
+	return c.breaker.State() == BreakerClosed
+}
+
+// Ping - самый дешёвый запрос, которым можно проверить, ожил ли API
+func (c *Client) Ping(ctx context.Context) error {
+	var resp struct{}
+	return c.get(ctx, "/campuses", nil, &resp)
+}
+
+// Participant возвращает профиль участника как есть, без проверок.
+func (c *Client) Participant(ctx context.Context, login string) (*Participant, error) {
+	var participant Participant
+	if err := c.get(ctx, "/participants/"+url.PathEscape(login), nil, &participant); err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// CheckUser проверяет, что ник принадлежит студенту основного обучения с рабочим профилем.
+func (c *Client) CheckUser(ctx context.Context, login string) (*Participant, error) {
+	participant, err := c.Participant(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if participant.ParallelName != CoreProgram {
+		return nil, ErrNotCoreProgram
+	}
+	if participant.Status == ParticipantBlocked {
+		return nil, ErrProfileBlocked
+	}
+	c.logger.Debug(ctx, fmt.Sprintf("CheckUser: User found: %s", participant.Login))
+	return participant, nil
+}
+
+// ParticipantProjects возвращает проекты участника, status - фильтр вроде ProjectInProgress, пусто - все.
+func (c *Client) ParticipantProjects(ctx context.Context, login, status string, opts ListOptions) ([]ParticipantProject, error) {
+	query := opts.values()
+	if status != "" {
+		query.Set("status", status)
+	}
+	var resp struct {
+		Projects []ParticipantProject `json:"projects"`
+	}
+	if err := c.get(ctx, "/participants/"+url.PathEscape(login)+"/projects", query, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Projects, nil
+}
+
+// ParticipantSkills возвращает навыки участника.
+func (c *Client) ParticipantSkills(ctx context.Context, login string, opts ListOptions) ([]Skill, error) {
+	var resp struct {
+		Skills []Skill `json:"skills"`
+	}
+	if err := c.get(ctx, "/participants/"+url.PathEscape(login)+"/skills", opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Skills, nil
+}
+
+// ParticipantBadges возвращает значки участника.
+func (c *Client) ParticipantBadges(ctx context.Context, login string, opts ListOptions) ([]Badge, error) {
+	var resp struct {
+		Badges []Badge `json:"badges"`
+	}
+	if err := c.get(ctx, "/participants/"+url.PathEscape(login)+"/badges", opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Badges, nil
+}
+
+// ParticipantCoalition возвращает коалицию участника.
+func (c *Client) ParticipantCoalition(ctx context.Context, login string) (*ParticipantCoalition, error) {
+	var coalition ParticipantCoalition
+	if err := c.get(ctx, "/participants/"+url.PathEscape(login)+"/coalition", nil, &coalition); err != nil {
+		return nil, err
+	}
+	return &coalition, nil
+}
+
+// Campuses возвращает все кампусы.
+func (c *Client) Campuses(ctx context.Context) ([]Campus, error) {
+	var resp struct {
+		Campuses []Campus `json:"campuses"`
+	}
+	if err := c.get(ctx, "/campuses", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Campuses, nil
+}
+
+// CampusParticipants возвращает логины участников кампуса.
+func (c *Client) CampusParticipants(ctx context.Context, campusID string, opts ListOptions) ([]string, error) {
+	var resp struct {
+		Participants []string `json:"participants"`
+	}
+	if err := c.get(ctx, "/campuses/"+url.PathEscape(campusID)+"/participants", opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Participants, nil
+}
+
+// CampusCoalitions возвращает коалиции кампуса.
+func (c *Client) CampusCoalitions(ctx context.Context, campusID string, opts ListOptions) ([]Coalition, error) {
+	var resp struct {
+		Coalitions []Coalition `json:"coalitions"`
+	}
+	if err := c.get(ctx, "/campuses/"+url.PathEscape(campusID)+"/coalitions", opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Coalitions, nil
+}
+
+// CoalitionParticipants возвращает логины участников коалиции.
+func (c *Client) CoalitionParticipants(ctx context.Context, coalitionID int64, opts ListOptions) ([]string, error) {
+	var resp struct {
+		Participants []string `json:"participants"`
+	}
+	if err := c.get(ctx, "/coalitions/"+strconv.FormatInt(coalitionID, 10)+"/participants", opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Participants, nil
+}
+
+// CampusClusters возвращает кластеры кампуса.
+func (c *Client) CampusClusters(ctx context.Context, campusID string) ([]Cluster, error) {
+	var resp struct {
+		Clusters []Cluster `json:"clusters"`
+	}
+	if err := c.get(ctx, "/campuses/"+url.PathEscape(campusID)+"/clusters", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Clusters, nil
+}
+
+// ClusterMap возвращает места кластера, occupied - только занятые.
+func (c *Client) ClusterMap(ctx context.Context, clusterID int64, occupied bool, opts ListOptions) ([]Workplace, error) {
+	query := opts.values()
+	if occupied {
+		query.Set("occupied", "true")
+	}
+	var resp struct {
+		ClusterMap []Workplace `json:"clusterMap"`
+	}
+	if err := c.get(ctx, "/clusters/"+strconv.FormatInt(clusterID, 10)+"/map", query, &resp); err != nil {
+		return nil, err
+	}
+	return resp.ClusterMap, nil
+}
+
+// Events возвращает события кампуса участника API за период.
+func (c *Client) Events(ctx context.Context, filter EventFilter, opts ListOptions) ([]Event, error) {
+	query := opts.values()
+	query.Set("from", filter.From.UTC().Format(time.RFC3339))
+	query.Set("to", filter.To.UTC().Format(time.RFC3339))
+	if filter.Type != "" {
+		query.Set("type", filter.Type)
+	}
+	var resp struct {
+		Events []Event `json:"events"`
+	}
+	if err := c.get(ctx, "/events", query, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// get выполняет GET через предохранитель и очередь и разбирает JSON в out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	generation, err := c.breaker.Allow()
+	if err != nil {
+		return err
+	}
+	err = c.do(ctx, path, query, out)
+	c.breaker.Record(generation, err)
+	return err
+}
+
+func (c *Client) do(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.logger.Error(ctx, fmt.Sprintf("School API request failed: %v", err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		// BearerTransport уже повторил запрос с новым токеном
+		c.logger.Error(ctx, "Re-authentication failed")
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		c.logger.Debug(ctx, fmt.Sprintf("School API: not found: %s", endpoint))
+		return ErrNotFound
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		err := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		err := fmt.Errorf("failed to parse response: %w", err)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package school
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrNotFound       = errors.New("not found")                   // API ответил 404
+	ErrUnauthorized   = errors.New("unauthorized after retry")    // токен не приняли даже после переавторизации
+	ErrNotCoreProgram = errors.New("not core program")            // участник не из основного обучения
+	ErrProfileBlocked = errors.New("profile blocked")             // профиль заблокирован
+	ErrQueueFull      = errors.New("school api queue is full")    // в очереди нет места, попробуйте позже
+	ErrQueueStopped   = errors.New("school api queue is stopped") // очередь остановлена вместе с ботом
+
+	ErrTemporarilyUnavailable = errors.New("school api temporarily unavailable") // сеть или 5xx и после повторов
+	ErrRateLimited            = errors.New("school api rate limit exceeded")     // 429 и после повторов
+	ErrCircuitOpen            = errors.New("school api circuit breaker is open") // API лежит, запрос даже не отправлялся
+)
+
+// IsTemporary - запрос не удался не из-за данных, его стоит повторить позже
+func IsTemporary(err error) bool {
+	return errors.Is(err, ErrTemporarilyUnavailable) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrQueueFull) || errors.Is(err, ErrCircuitOpen)
+}
+
+// StatusError - API ответил неожиданным статусом
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("school api: status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap даёт проверить временные ошибки через errors.Is
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrTemporarilyUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package school
+
+import "time"
+
+// Participant - профиль участника платформы.
+type Participant struct {
+	Login          string `json:"login"`
+	ClassName      string `json:"className"`
+	ParallelName   string `json:"parallelName"`
+	ExpValue       int    `json:"expValue"`
+	Level          int    `json:"level"`
+	ExpToNextLevel int    `json:"expToNextLevel"`
+	Campus         struct {
+		ID        string `json:"id"`
+		ShortName string `json:"shortName"`
+	} `json:"campus"`
+	Status string `json:"status"`
+}
+
+// Статусы участника
+const (
+	ParticipantActive  = "ACTIVE"
+	ParticipantBlocked = "BLOCKED"
+	ParticipantFrozen  = "FROZEN"
+)
+
+// CoreProgram - параллель основного обучения, только её студентов пускаем в чаты
+const CoreProgram = "Core program"
+
+// ParticipantProject - проект участника и его результат.
+type ParticipantProject struct {
+	ID                 int64      `json:"id"`
+	Title              string     `json:"title"`
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	FinalPercentage    *int       `json:"finalPercentage"`
+	CompletionDateTime *time.Time `json:"completionDateTime"`
+	CourseID           *int64     `json:"courseId"`
+	TeamMembers        []struct {
+		Login    string `json:"login"`
+		IsLeader bool   `json:"isTeamLead"`
+	} `json:"teamMembers"`
+}
+
+// Статусы проекта участника, можно передать фильтром в ParticipantProjects
+const (
+	ProjectAssigned      = "ASSIGNED"
+	ProjectRegistered    = "REGISTERED"
+	ProjectInProgress    = "IN_PROGRESS"
+	ProjectInReviews     = "IN_REVIEWS"
+	ProjectAccepted      = "ACCEPTED"
+	ProjectFailed        = "FAILED"
+	ProjectUnavailable   = "UNAVAILABLE"
+	ProjectCompleted     = "COMPLETED"
+	ProjectTeamSearching = "TEAM_SEARCHING"
+)
+
+// Skill - навык участника в очках.
+type Skill struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+}
+
+// Badge - значок участника.
+type Badge struct {
+	Name            string    `json:"name"`
+	ReceiptDateTime time.Time `json:"receiptDateTime"`
+	IconURL         string    `json:"iconURL"`
+}
+
+// ParticipantCoalition - коалиция (трайб) участника и его место в ней.
+type ParticipantCoalition struct {
+	CoalitionID int64  `json:"coalitionId"`
+	Name        string `json:"name"`
+	Rank        int    `json:"rank"`
+}
+
+// Coalition - коалиция кампуса.
+type Coalition struct {
+	CoalitionID int64  `json:"coalitionId"`
+	Name        string `json:"name"`
+}
+
+// Campus - кампус Школы 21.
+type Campus struct {
+	ID        string `json:"id"`
+	ShortName string `json:"shortName"`
+	FullName  string `json:"fullName"`
+}
+
+// Cluster - кластер кампуса.
+type Cluster struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	Capacity          int    `json:"capacity"`
+	AvailableCapacity int    `json:"availableCapacity"`
+	Floor             int    `json:"floor"`
+}
+
+// Workplace - место в кластере, Login пустой, если место свободно.
+type Workplace struct {
+	ClusterID   int64  `json:"clusterId"`
+	ClusterName string `json:"clusterName"`
+	Row         string `json:"row"`
+	Number      int    `json:"number"`
+	Login       string `json:"login"`
+	StageGroup  string `json:"stageGroupName"`
+	StageName   string `json:"stageName"`
+}
+
+// Event - событие кампуса.
+type Event struct {
+	ID            int64     `json:"id"`
+	Type          string    `json:"type"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Location      string    `json:"location"`
+	StartDateTime time.Time `json:"startDateTime"`
+	EndDateTime   time.Time `json:"endDateTime"`
+	Organizers    []string  `json:"organizers"`
+	Capacity      int       `json:"capacity"`
+	RegisterCount int       `json:"registerCount"`
+}
+
+// EventFilter - какие события искать. From и To обязательны для API.
+type EventFilter struct {
+	From time.Time
+	To   time.Time
+	Type string // пусто - любые
+}
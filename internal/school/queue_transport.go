@@ -0,0 +1,14 @@
+package school
+
+import "net/http"
+
+// QueueTransport отправляет запросы http.Client через ApiQueue,
+// так что на них действует общий лимит запросов к API.
+// Контекст запроса доезжает до очереди: отменённый запрос в API не уйдёт.
+type QueueTransport struct {
+	Queue ApiQueue
+}
+
+func (t *QueueTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.Queue.Do(req)
+}
@@ -0,0 +1,79 @@
+package school
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryAfter - дольше API ждать не будем, даже если он попросит
+const maxRetryAfter = 5 * time.Minute
+
+// RetryPolicy - как повторять запросы, которые упали по сети, получили 5xx или 429.
+// Повторяются только идемпотентные запросы, а 429 - любые: такой запрос API не выполнял.
+type RetryPolicy struct {
+	MaxAttempts int           // всего попыток вместе с первой, 1 - без повторов
+	BaseDelay   time.Duration // пауза перед первым повтором, дальше удваивается
+	MaxDelay    time.Duration // потолок паузы
+}
+
+// DefaultRetryPolicy - три попытки с паузами около 0.5 и 1 секунды
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// backoff - пауза перед повтором номер attempt (с единицы): экспонента со случайным
+// разбросом в пределах второй половины, чтобы повторы не приходили в API все разом
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		delay = min(p.BaseDelay<<shift, p.MaxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// idempotent - повтор запроса ничего не сломает, и его тело можно отправить заново
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return replayable(req)
+}
+
+// replayable - тело запроса можно прочитать ещё раз
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// temporaryStatus - ответ, после которого запрос стоит повторить
+func temporaryStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter разбирает заголовок Retry-After: секунды или дата. 0 - заголовка нет.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	}
+	return min(max(delay, 0), maxRetryAfter)
+}
@@ -0,0 +1,255 @@
+package school
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"morty-smith-34-c/pkg/logger"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshBefore - за сколько до истечения токен обновляется заранее,
+// чтобы запрос не ушёл с токеном, который протухнет по дороге
+const refreshBefore = 30 * time.Second
+
+// Token - токены Keycloak
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time // когда истечёт access_token
+	// когда истечёт refresh_token, нулевое время - срок неизвестен
+	RefreshExpiry time.Time
+}
+
+// valid - токен можно отдавать, он не истечёт в ближайшие refreshBefore
+func (t *Token) valid(now time.Time) bool {
+	return t != nil && t.AccessToken != "" && now.Add(refreshBefore).Before(t.Expiry)
+}
+
+// canRefresh - refresh_token ещё жив
+func (t *Token) canRefresh(now time.Time) bool {
+	return t != nil && t.RefreshToken != "" && (t.RefreshExpiry.IsZero() || now.Before(t.RefreshExpiry))
+}
+
+// tokenResponse - ответ Keycloak на запрос токена
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// TokenSource выдаёт access_token для School API. Токен обновляется заранее, до истечения,
+// а если он нужен сразу многим запросам, в Keycloak уходит один запрос на всех.
+type TokenSource struct {
+	username string
+	password string
+	tokenURL string
+	http     *http.Client // запросы к Keycloak идут через ту же очередь, но без BearerTransport
+	token    *Token
+	mu       sync.RWMutex
+	refresh  singleflight.Group
+	logger   *logger.Logger
+}
+
+// NewTokenSource создаёт источник токенов, tokenURL - адрес token-эндпоинта Keycloak
+func NewTokenSource(username, password, tokenURL string, apiQueue ApiQueue, logger *logger.Logger) *TokenSource {
+	return &TokenSource{
+		username: username,
+		password: password,
+		tokenURL: tokenURL,
+		http:     &http.Client{Transport: &QueueTransport{Queue: apiQueue}},
+		logger:   logger,
+	}
+}
+
+// Token возвращает действующий access_token, при необходимости обновляя его
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.RLock()
+	token := s.token
+	s.mu.RUnlock()
+	if token.valid(time.Now()) {
+		return token.AccessToken, nil
+	}
+	return s.renew(ctx)
+}
+
+// Authenticate получает токены по логину и паролю, даже если текущие ещё действуют.
+// Вызывается при старте, чтобы неверные логин и пароль были видны сразу.
+func (s *TokenSource) Authenticate(ctx context.Context) error {
+	s.Invalidate("")
+	_, err := s.renew(ctx)
+	return err
+}
+
+// Invalidate забывает access_token, который не принял API. Пустая строка - забыть любой.
+// Refresh-токен остаётся, следующий Token обновит access_token через него.
+func (s *TokenSource) Invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && (accessToken == "" || s.token.AccessToken == accessToken) {
+		s.token.AccessToken = ""
+	}
+}
+
+// renew обновляет токен одним запросом на всех ждущих
+func (s *TokenSource) renew(ctx context.Context) (string, error) {
+	// Обновление общее, поэтому не должно отменяться вместе с запросом первого пришедшего
+	result := s.refresh.DoChan("token", func() (interface{}, error) {
+		return s.fetch(context.WithoutCancel(ctx))
+	})
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fetch получает новый токен: через refresh_token, если он жив, иначе по логину и паролю
+func (s *TokenSource) fetch(ctx context.Context) (string, error) {
+	s.mu.RLock()
+	current := s.token
+	s.mu.RUnlock()
+	// Пока ждали своей очереди, токен мог обновить кто-то другой
+	if current.valid(time.Now()) {
+		return current.AccessToken, nil
+	}
+
+	if current.canRefresh(time.Now()) {
+		token, err := s.request(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {current.RefreshToken},
+		})
+		if err == nil {
+			s.store(token)
+			s.logger.Info(ctx, "Tokens successfully refreshed")
+			return token.AccessToken, nil
+		}
+		if !errors.Is(err, errInvalidGrant) {
+			return "", fmt.Errorf("failed to refresh tokens: %w", err)
+		}
+		s.logger.Warn(ctx, "Refresh token is invalid, re-authenticating with username and password")
+	}
+
+	token, err := s.request(ctx, url.Values{
+		"grant_type": {"password"},
+		"username":   {s.username},
+		"password":   {s.password},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate: %w", err)
+	}
+	s.store(token)
+	s.logger.Info(ctx, "Successfully authenticated")
+	return token.AccessToken, nil
+}
+
+// errInvalidGrant - Keycloak не принял refresh_token
+var errInvalidGrant = errors.New("invalid_grant")
+
+func (s *TokenSource) request(ctx context.Context, form url.Values) (*Token, error) {
+	form.Set("client_id", "s21-open-api")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		s.logger.Error(ctx, fmt.Sprintf("Token request failed: %v", err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "invalid_grant") && form.Get("grant_type") == "refresh_token" {
+			return nil, errInvalidGrant
+		}
+		err := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+		s.logger.Error(ctx, err.Error())
+		return nil, err
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		err := fmt.Errorf("failed to parse response: %w", err)
+		s.logger.Error(ctx, err.Error())
+		return nil, err
+	}
+	now := time.Now()
+	token := &Token{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		Expiry:       now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
+	}
+	if tokenResp.RefreshExpiresIn > 0 {
+		token.RefreshExpiry = now.Add(time.Duration(tokenResp.RefreshExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func (s *TokenSource) store(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// BearerTransport подставляет access_token в запросы. Если API ответил 401,
+// токен забывается и запрос без тела повторяется один раз с новым.
+type BearerTransport struct {
+	Source *TokenSource
+	Base   http.RoundTripper // nil - http.DefaultTransport
+}
+
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, token, err := t.roundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	t.Source.Invalidate(token)
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// Тело уже прочитано, повторить нечем
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	resp, _, err = t.roundTrip(retry)
+	return resp, err
+}
+
+func (t *BearerTransport) roundTrip(req *http.Request) (*http.Response, string, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get access token: %w", err)
+	}
+	// RoundTripper не должен менять исходный запрос
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(authorized)
+	return resp, token, err
+}
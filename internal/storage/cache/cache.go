@@ -2,14 +2,23 @@ package cache
 
 import (
 	"context"
+	"morty-smith-34-c/internal/app/entity"
 	"morty-smith-34-c/internal/app/usecase"
 	"sync"
 )
 
+// chatTextKey - правила и FAQ одного чата лежат под разными ключами
+type chatTextKey struct {
+	chatID int64
+	kind   string
+}
+
 type ChatCache struct {
 	threadIdCache sync.Map
-	rulesCache    sync.Map
-	faqCache      sync.Map
+	textCache     sync.Map // chatTextKey -> *entity.ChatText
+	slowModeCache sync.Map
+	autoFaqCache  sync.Map
+	languageCache sync.Map
 }
 
 func NewChatCache() *ChatCache {
@@ -30,32 +39,60 @@ func (c *ChatCache) SetThreadID(chatID int64, threadID int) {
 	c.threadIdCache.Store(chatID, threadID)
 }
 
-func (c *ChatCache) GetRules(chatID int64) (string, bool) {
-	value, ok := c.rulesCache.Load(chatID)
+// GetText возвращает правила или FAQ чата, если они есть в кеше.
+func (c *ChatCache) GetText(chatID int64, kind string) (*entity.ChatText, bool) {
+	value, ok := c.textCache.Load(chatTextKey{chatID: chatID, kind: kind})
 	if !ok {
-		return "", false
+		return nil, false
 	}
-	return value.(string), true
+	return value.(*entity.ChatText), true
 }
 
-func (c *ChatCache) SetRules(chatID int64, rulesLink string) {
-	c.rulesCache.Store(chatID, rulesLink)
+func (c *ChatCache) SetText(text *entity.ChatText) {
+	c.textCache.Store(chatTextKey{chatID: text.ChatID, kind: text.Kind}, text)
 }
 
-func (c *ChatCache) GetFaq(chatID int64) (string, bool) {
-	value, ok := c.faqCache.Load(chatID)
+// GetSlowModeDelay возвращает задержку медленного режима для чата, 0 - выключен.
+func (c *ChatCache) GetSlowModeDelay(chatID int64) int {
+	value, ok := c.slowModeCache.Load(chatID)
+	if !ok {
+		return 0
+	}
+	return value.(int)
+}
+
+func (c *ChatCache) SetSlowModeDelay(chatID int64, delay int) {
+	c.slowModeCache.Store(chatID, delay)
+}
+
+// GetAutoFaqThreshold возвращает порог автоответов из FAQ для чата, 0 - выключены.
+func (c *ChatCache) GetAutoFaqThreshold(chatID int64) float64 {
+	value, ok := c.autoFaqCache.Load(chatID)
+	if !ok {
+		return 0
+	}
+	return value.(float64)
+}
+
+func (c *ChatCache) SetAutoFaqThreshold(chatID int64, threshold float64) {
+	c.autoFaqCache.Store(chatID, threshold)
+}
+
+// GetLanguage возвращает язык Морти в чате, false - чат не активирован.
+func (c *ChatCache) GetLanguage(chatID int64) (string, bool) {
+	value, ok := c.languageCache.Load(chatID)
 	if !ok {
 		return "", false
 	}
 	return value.(string), true
 }
 
-func (c *ChatCache) SetFaq(chatID int64, rulesLink string) {
-	c.faqCache.Store(chatID, rulesLink)
+func (c *ChatCache) SetLanguage(chatID int64, language string) {
+	c.languageCache.Store(chatID, language)
 }
 
 // LoadFromDatabase загружает данные из базы в кеш.
-func (c *ChatCache) LoadFromDatabase(ctx context.Context, chatUseCase *usecase.ChatUseCase) error {
+func (c *ChatCache) LoadFromDatabase(ctx context.Context, chatUseCase *usecase.ChatUseCase, chatTextUseCase *usecase.ChatTextUseCase) error {
 	chats, err := chatUseCase.GetAllChats(ctx)
 	if err != nil {
 		return err
@@ -63,12 +100,17 @@ func (c *ChatCache) LoadFromDatabase(ctx context.Context, chatUseCase *usecase.C
 
 	for _, chat := range chats {
 		c.SetThreadID(chat.ChatID, chat.ThreadID)
-		if chat.RulesLink != nil {
-			c.SetRules(chat.ChatID, *chat.RulesLink)
-		}
-		if chat.FaqLink != nil {
-			c.SetFaq(chat.ChatID, *chat.FaqLink)
-		}
+		c.SetSlowModeDelay(chat.ChatID, chat.SlowModeDelay)
+		c.SetAutoFaqThreshold(chat.ChatID, chat.AutoFaqThreshold)
+		c.SetLanguage(chat.ChatID, chat.Language)
+	}
+
+	texts, err := chatTextUseCase.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, text := range texts {
+		c.SetText(text)
 	}
 	return nil
 }
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"morty-smith-34-c/internal/school"
+)
+
+type profileEntry struct {
+	profile   *school.Participant
+	expiresAt time.Time
+}
+
+// ProfileCache хранит профили из School API, чтобы /whois и /me не дёргали API на каждый вызов.
+// Логины присылают пользователи, поэтому устаревшие профили вычищает Start, а не только Get.
+type ProfileCache struct {
+	profiles sync.Map
+	ttl      time.Duration
+}
+
+func NewProfileCache(ttl time.Duration) *ProfileCache {
+	return &ProfileCache{ttl: ttl}
+}
+
+// Get возвращает профиль, если он есть и ещё не устарел.
+func (c *ProfileCache) Get(login string) (*school.Participant, bool) {
+	value, ok := c.profiles.Load(strings.ToLower(login))
+	if !ok {
+		return nil, false
+	}
+	entry := value.(profileEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.profiles.Delete(strings.ToLower(login))
+		return nil, false
+	}
+	return entry.profile, true
+}
+
+// Set запоминает профиль на время ttl.
+func (c *ProfileCache) Set(login string, profile *school.Participant) {
+	c.profiles.Store(strings.ToLower(login), profileEntry{
+		profile:   profile,
+		expiresAt: time.Now().Add(c.ttl),
+	})
+}
+
+// Start раз в ttl выбрасывает устаревшие профили, пока не отменят ctx.
+func (c *ProfileCache) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.ttl)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				c.sweep(now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// sweep удаляет профили, устаревшие к now
+func (c *ProfileCache) sweep(now time.Time) {
+	c.profiles.Range(func(key, value any) bool {
+		if now.After(value.(profileEntry).expiresAt) {
+			c.profiles.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
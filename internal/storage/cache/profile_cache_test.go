@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"morty-smith-34-c/internal/school"
+	"testing"
+	"time"
+)
+
+func TestProfileCacheSweep(t *testing.T) {
+	c := NewProfileCache(time.Minute)
+	c.Set("Brieyele", &school.Participant{Login: "brieyele"})
+	c.Set("rickyaso", &school.Participant{Login: "rickyaso"})
+
+	c.sweep(time.Now())
+	if _, ok := c.Get("brieyele"); !ok {
+		t.Fatal("fresh profile was swept")
+	}
+
+	c.sweep(time.Now().Add(2 * time.Minute))
+	left := 0
+	c.profiles.Range(func(key, value any) bool {
+		left++
+		return true
+	})
+	if left != 0 {
+		t.Errorf("%d expired profiles left after sweep", left)
+	}
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// UserCache запоминает username -> Telegram ID всех, кого видел бот.
+// Bot API не умеет искать пользователя по username, поэтому собираем сами.
+type UserCache struct {
+	usernameCache sync.Map
+}
+
+func NewUserCache() *UserCache {
+	return &UserCache{}
+}
+
+// Remember сохраняет username пользователя, если он есть.
+// Возвращает true, если такого username ещё не было или он сменил владельца.
+func (c *UserCache) Remember(user *models.User) bool {
+	if user == nil || user.Username == "" {
+		return false
+	}
+	previous, loaded := c.usernameCache.Swap(strings.ToLower(user.Username), user.ID)
+	return !loaded || previous.(int64) != user.ID
+}
+
+// GetIDByUsername возвращает Telegram ID по username без @.
+func (c *UserCache) GetIDByUsername(username string) (int64, bool) {
+	value, ok := c.usernameCache.Load(strings.ToLower(strings.TrimPrefix(username, "@")))
+	if !ok {
+		return 0, false
+	}
+	return value.(int64), true
+}
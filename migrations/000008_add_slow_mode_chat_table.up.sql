@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN slow_mode_delay INTEGER NOT NULL DEFAULT 0;
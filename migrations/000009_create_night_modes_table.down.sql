@@ -0,0 +1 @@
+DROP TABLE IF EXISTS night_modes;
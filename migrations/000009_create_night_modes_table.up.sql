@@ -0,0 +1,11 @@
+CREATE TABLE night_modes (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL UNIQUE REFERENCES chats (chat_id) ON DELETE CASCADE,
+    start_minute INTEGER NOT NULL,
+    end_minute INTEGER NOT NULL,
+    restriction VARCHAR(16) NOT NULL DEFAULT 'media',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    saved_permissions JSONB DEFAULT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+CREATE TABLE mutes (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    moderator_id BIGINT NOT NULL,
+    reason TEXT DEFAULT NULL,
+    until TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (chat_id, telegram_id)
+);
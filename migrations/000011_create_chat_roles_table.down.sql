@@ -0,0 +1,6 @@
+UPDATE users u
+SET role = cr.role
+FROM chat_roles cr
+WHERE cr.telegram_id = u.telegram_id AND u.role = 'user' AND cr.role IN ('moder', 'admin');
+
+DROP TABLE IF EXISTS chat_roles;
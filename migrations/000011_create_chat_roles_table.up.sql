@@ -0,0 +1,18 @@
+CREATE TABLE chat_roles (
+    id SERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL,
+    chat_id BIGINT NOT NULL REFERENCES chats (chat_id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL DEFAULT 'user',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (telegram_id, chat_id)
+);
+
+-- Модераторы и админы раньше действовали во всех чатах, сохраняем это поведение
+INSERT INTO chat_roles (telegram_id, chat_id, role)
+SELECT u.telegram_id, c.chat_id, u.role
+FROM users u
+CROSS JOIN chats c
+WHERE u.role IN ('moder', 'admin');
+
+-- Глобальной остаётся только роль суперадмина
+UPDATE users SET role = 'user' WHERE role IN ('moder', 'admin');
@@ -0,0 +1,8 @@
+CREATE TABLE chat_permissions (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    capability VARCHAR(32) NOT NULL,
+    allowed BOOLEAN NOT NULL,
+    UNIQUE (chat_id, role, capability)
+);
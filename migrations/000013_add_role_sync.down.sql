@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS role_sync_opt_outs;
+ALTER TABLE chat_roles DROP COLUMN IF EXISTS source;
@@ -0,0 +1,11 @@
+-- Откуда взялась роль: выдана командой /role или синхронизирована с админами Telegram
+ALTER TABLE chat_roles ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'manual';
+
+-- Пользователи, которым синхронизация с админами Telegram не меняет роль
+CREATE TABLE role_sync_opt_outs (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats (chat_id) ON DELETE CASCADE,
+    telegram_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (chat_id, telegram_id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE chat_members (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats (chat_id) ON DELETE CASCADE,
+    telegram_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL, -- pending, member, left, kicked, banned
+    joined_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    verified_at TIMESTAMP DEFAULT NULL,
+    left_at TIMESTAMP DEFAULT NULL,
+    UNIQUE (chat_id, telegram_id)
+);
+
+CREATE INDEX idx_chat_members_telegram_id ON chat_members (telegram_id);
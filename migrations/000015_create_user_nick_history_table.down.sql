@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_nick_history;
+
+UPDATE users SET school_name = telegram_id::text WHERE school_name IS NULL;
+ALTER TABLE users ALTER COLUMN school_name SET NOT NULL;
@@ -0,0 +1,19 @@
+-- Одобренные вручную без школьного ника больше не получают ник из Telegram ID
+ALTER TABLE users ALTER COLUMN school_name DROP NOT NULL;
+UPDATE users SET school_name = NULL WHERE school_name = telegram_id::text;
+
+CREATE TABLE user_nick_history (
+    id SERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL,
+    school_name VARCHAR(16) DEFAULT NULL, -- NULL - одобрен без школьного ника
+    source VARCHAR(16) NOT NULL,          -- self_verified, manual, legacy
+    set_by BIGINT DEFAULT NULL,           -- кто выдал ник через /save
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_nick_history_telegram_id ON user_nick_history (telegram_id);
+
+-- Откуда взялись уже сохранённые ники, мы не знаем
+INSERT INTO user_nick_history (telegram_id, school_name, source, created_at)
+SELECT telegram_id, school_name, 'legacy', created_at
+FROM users;
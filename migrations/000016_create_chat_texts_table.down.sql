@@ -0,0 +1,13 @@
+ALTER TABLE chats ADD COLUMN rules_link TEXT DEFAULT NULL;
+ALTER TABLE chats ADD COLUMN faq_link TEXT DEFAULT NULL;
+
+-- Разметка и медиа при откате теряются
+UPDATE chats SET rules_link = t.text
+FROM chat_texts t
+WHERE t.chat_id = chats.chat_id AND t.kind = 'rules';
+
+UPDATE chats SET faq_link = t.text
+FROM chat_texts t
+WHERE t.chat_id = chats.chat_id AND t.kind = 'faq';
+
+DROP TABLE IF EXISTS chat_texts;
@@ -0,0 +1,22 @@
+CREATE TABLE chat_texts (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats (chat_id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,              -- rules, faq
+    text TEXT NOT NULL DEFAULT '',
+    entities JSONB DEFAULT NULL,            -- MessageEntity из Telegram, смещения в UTF-16
+    media_type VARCHAR(16) DEFAULT NULL,    -- photo, video, document, animation
+    media_file_id TEXT DEFAULT NULL,
+    updated_by BIGINT DEFAULT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (chat_id, kind)
+);
+
+-- Старые правила и FAQ были простым текстом без разметки
+INSERT INTO chat_texts (chat_id, kind, text)
+SELECT chat_id, 'rules', rules_link FROM chats WHERE rules_link IS NOT NULL;
+
+INSERT INTO chat_texts (chat_id, kind, text)
+SELECT chat_id, 'faq', faq_link FROM chats WHERE faq_link IS NOT NULL;
+
+ALTER TABLE chats DROP COLUMN rules_link;
+ALTER TABLE chats DROP COLUMN faq_link;
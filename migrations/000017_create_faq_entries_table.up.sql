@@ -0,0 +1,14 @@
+CREATE TABLE faq_entries (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT DEFAULT NULL REFERENCES chats (chat_id) ON DELETE CASCADE, -- NULL - общий для всех чатов
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    answer_entities JSONB DEFAULT NULL,      -- MessageEntity из Telegram, смещения в UTF-16
+    tags TEXT NOT NULL DEFAULT '',           -- категории через запятую
+    keywords TEXT NOT NULL DEFAULT '',       -- слова для поиска через запятую
+    created_by BIGINT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_faq_entries_chat_id ON faq_entries (chat_id);
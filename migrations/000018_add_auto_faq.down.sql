@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS faq_rejections;
+ALTER TABLE faq_entries DROP COLUMN patterns;
+ALTER TABLE chats DROP COLUMN auto_faq_threshold;
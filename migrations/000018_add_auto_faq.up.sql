@@ -0,0 +1,14 @@
+ALTER TABLE chats ADD COLUMN auto_faq_threshold REAL NOT NULL DEFAULT 0; -- 0 - автоответы выключены
+
+ALTER TABLE faq_entries ADD COLUMN patterns TEXT NOT NULL DEFAULT ''; -- регулярки, по одной на строку
+
+CREATE TABLE faq_rejections (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats (chat_id) ON DELETE CASCADE,
+    entry_id INTEGER NOT NULL REFERENCES faq_entries (id) ON DELETE CASCADE,
+    text TEXT NOT NULL,                  -- сообщение, на которое Морти ответил невпопад
+    rejected_by BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_faq_rejections_chat_id ON faq_rejections (chat_id);
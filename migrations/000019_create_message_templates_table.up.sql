@@ -0,0 +1,9 @@
+CREATE TABLE message_templates (
+    id SERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats (chat_id) ON DELETE CASCADE,
+    name VARCHAR(32) NOT NULL,   -- welcome, welcome_back, nick_not_found...
+    body TEXT NOT NULL,          -- text/template, результат в MarkdownV2
+    updated_by BIGINT DEFAULT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (chat_id, name)
+);
@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN language VARCHAR(8) NOT NULL DEFAULT 'ru'; -- язык Морти в чате, auto - язык клиента каждого участника
@@ -0,0 +1,2 @@
+ALTER TABLE faq_entries DROP COLUMN media_file_id;
+ALTER TABLE faq_entries DROP COLUMN media_type;
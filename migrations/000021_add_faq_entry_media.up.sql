@@ -0,0 +1,2 @@
+ALTER TABLE faq_entries ADD COLUMN media_type VARCHAR(16) DEFAULT NULL; -- photo, video, document, animation
+ALTER TABLE faq_entries ADD COLUMN media_file_id TEXT DEFAULT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_nick_history_school_name;
+DROP INDEX IF EXISTS idx_users_username;
+ALTER TABLE users DROP COLUMN username;
@@ -0,0 +1,5 @@
+-- Последний username из Telegram, чтобы находить цель команд по @username и после перезапуска
+ALTER TABLE users ADD COLUMN username VARCHAR(32) DEFAULT NULL;
+
+CREATE INDEX idx_users_username ON users (username);
+CREATE INDEX idx_user_nick_history_school_name ON user_nick_history (school_name);
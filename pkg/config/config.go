@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -22,6 +23,15 @@ type Config struct {
 	SchoolPassword   string
 	SchoolTokenURL   string
 	SchoolBaseApiURL string
+	// Повторы запросов к School API: всего попыток и границы паузы между ними
+	SchoolRetryAttempts  int
+	SchoolRetryBaseDelay time.Duration
+	SchoolRetryMaxDelay  time.Duration
+	// Предохранитель School API: после скольких сбоев подряд считать API лежащим и как часто пробовать снова
+	SchoolBreakerThreshold int
+	SchoolBreakerCooldown  time.Duration
+	AlertsChatID           int64         // чат модераторов для оповещений, 0 - только в лог
+	RoleSyncInterval       time.Duration // 0 - синхронизация с админами Telegram выключена
 }
 
 func LoadConfig() (*Config, error) {
@@ -54,6 +64,34 @@ func LoadConfig() (*Config, error) {
 	cfg.SchoolPassword = getEnv("SCHOOL_PASSWORD", "")
 	cfg.SchoolTokenURL = getEnv("SCHOOL_TOKEN_URL", "")
 	cfg.SchoolBaseApiURL = getEnv("SCHOOL_BASE_API_URL", "")
+	cfg.SchoolRetryAttempts, err = strconv.Atoi(getEnv("SCHOOL_RETRY_ATTEMPTS", "3"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.SchoolRetryBaseDelay, err = time.ParseDuration(getEnv("SCHOOL_RETRY_BASE_DELAY", "500ms"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.SchoolRetryMaxDelay, err = time.ParseDuration(getEnv("SCHOOL_RETRY_MAX_DELAY", "10s"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.SchoolBreakerThreshold, err = strconv.Atoi(getEnv("SCHOOL_BREAKER_THRESHOLD", "5"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.SchoolBreakerCooldown, err = time.ParseDuration(getEnv("SCHOOL_BREAKER_COOLDOWN", "30s"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.AlertsChatID, err = strconv.ParseInt(getEnv("ALERTS_CHAT_ID", "0"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RoleSyncInterval, err = time.ParseDuration(getEnv("ROLE_SYNC_INTERVAL", "0"))
+	if err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
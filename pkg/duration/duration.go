@@ -0,0 +1,125 @@
+// Package duration разбирает человеческие длительности вроде "2 дня 3 часа",
+// "1h30m", "2d", "3 weeks" или "навсегда".
+//
+// Грамматика:
+//
+//	duration = forever | term { [separator] term }
+//	term     = number [spaces] unit
+//	separator = "," | "и" | "and"
+//	forever  = "навсегда" | "бессрочно" | "forever" | "permanent" | "perm"
+//
+// Единицы: секунды, минуты, часы, дни, недели и месяцы (30 дней)
+// во всех русских падежных формах, английские слова и сокращения в стиле Go.
+package duration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Forever - бессрочная длительность.
+const Forever time.Duration = math.MaxInt64
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+)
+
+var (
+	ErrEmpty       = errors.New("duration is empty")
+	ErrUnknownUnit = errors.New("unknown duration unit")
+	ErrTooLong     = errors.New("duration is too long")
+)
+
+var units = map[string]time.Duration{
+	// секунды
+	"с": time.Second, "сек": time.Second, "секунда": time.Second, "секунду": time.Second,
+	"секунды": time.Second, "секунд": time.Second,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	// минуты
+	"м": time.Minute, "мин": time.Minute, "минута": time.Minute, "минуту": time.Minute,
+	"минуты": time.Minute, "минут": time.Minute,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	// часы
+	"ч": time.Hour, "час": time.Hour, "часа": time.Hour, "часов": time.Hour,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	// дни
+	"д": day, "дн": day, "день": day, "дня": day, "дней": day,
+	"d": day, "day": day, "days": day,
+	// недели
+	"н": week, "нед": week, "неделя": week, "неделю": week, "недели": week, "недель": week,
+	"w": week, "wk": week, "week": week, "weeks": week,
+	// месяцы
+	"мес": month, "месяц": month, "месяца": month, "месяцев": month,
+	"mo": month, "month": month, "months": month,
+}
+
+var foreverWords = map[string]bool{
+	"навсегда":  true,
+	"бессрочно": true,
+	"forever":   true,
+	"permanent": true,
+	"perm":      true,
+}
+
+var (
+	termRe      = regexp.MustCompile(`(?i)^\s*(?:(?:,|и|and)\s+|,)?\s*(\d+)\s*([a-zа-яё]+)`)
+	firstWordRe = regexp.MustCompile(`(?i)^\s*([a-zа-яё]+)`)
+)
+
+// Parse разбирает строку целиком. Лишний текст после длительности - ошибка.
+func Parse(input string) (time.Duration, error) {
+	d, rest, err := ParsePrefix(input)
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return 0, fmt.Errorf("unexpected %q after duration", strings.TrimSpace(rest))
+	}
+	return d, nil
+}
+
+// ParsePrefix разбирает длительность в начале строки и возвращает остаток,
+// например причину мута: "2ч спам в чате" -> 2h, "спам в чате".
+func ParsePrefix(input string) (time.Duration, string, error) {
+	rest := input
+
+	if m := firstWordRe.FindStringSubmatch(rest); m != nil && foreverWords[strings.ToLower(m[1])] {
+		return Forever, strings.TrimSpace(rest[len(m[0]):]), nil
+	}
+
+	var total time.Duration
+	parsed := false
+	for {
+		m := termRe.FindStringSubmatch(rest)
+		if m == nil {
+			break
+		}
+		unit, ok := units[strings.ToLower(m[2])]
+		if !ok {
+			if !parsed {
+				return 0, "", fmt.Errorf("%w: %q", ErrUnknownUnit, m[2])
+			}
+			// Дальше уже не длительность, а текст
+			break
+		}
+		value, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || value > int64((Forever-1-total)/unit) {
+			return 0, "", ErrTooLong
+		}
+		total += time.Duration(value) * unit
+		parsed = true
+		rest = rest[len(m[0]):]
+	}
+
+	if !parsed {
+		return 0, "", ErrEmpty
+	}
+	return total, strings.TrimSpace(rest), nil
+}
@@ -0,0 +1,128 @@
+package duration
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr error
+	}{
+		// единицы
+		{"30s", 30 * time.Second, nil},
+		{"45 сек", 45 * time.Second, nil},
+		{"1 секунду", time.Second, nil},
+		{"5m", 5 * time.Minute, nil},
+		{"5 минут", 5 * time.Minute, nil},
+		{"1 минута", time.Minute, nil},
+		{"3 минуты", 3 * time.Minute, nil},
+		{"10 mins", 10 * time.Minute, nil},
+		{"2h", 2 * time.Hour, nil},
+		{"2ч", 2 * time.Hour, nil},
+		{"5 часов", 5 * time.Hour, nil},
+		{"1 hour", time.Hour, nil},
+		{"2d", 2 * day, nil},
+		{"1 день", day, nil},
+		{"2 дня", 2 * day, nil},
+		{"3 weeks", 3 * week, nil},
+		{"1 неделю", week, nil},
+		{"1 месяц", month, nil},
+		{"2 months", 2 * month, nil},
+		{"2H", 2 * time.Hour, nil},
+		{"2 ДНЯ", 2 * day, nil},
+
+		// комбинации
+		{"1h30m", time.Hour + 30*time.Minute, nil},
+		{"1d2h3m4s", day + 2*time.Hour + 3*time.Minute + 4*time.Second, nil},
+		{"2 дня 3 часа", 2*day + 3*time.Hour, nil},
+		{"1 день, 2 часа и 5 минут", day + 2*time.Hour + 5*time.Minute, nil},
+		{"1 week and 2 days", week + 2*day, nil},
+		{"1h,30m", time.Hour + 30*time.Minute, nil},
+		{"  90 min  ", 90 * time.Minute, nil},
+
+		// навсегда
+		{"навсегда", Forever, nil},
+		{"Бессрочно", Forever, nil},
+		{"forever", Forever, nil},
+		{"perm", Forever, nil},
+
+		// переполнение
+		{"99999999999999999999 s", 0, ErrTooLong},
+		{"300000 weeks", 0, ErrTooLong},
+		{"106751 days 1 day", 0, ErrTooLong},
+
+		// пусто
+		{"", 0, ErrEmpty},
+		{"   ", 0, ErrEmpty},
+
+		// отрицательные
+		{"-5m", 0, ErrEmpty},
+		{"- 1 день", 0, ErrEmpty},
+
+		// мусор
+		{"abc", 0, ErrEmpty},
+		{"5", 0, ErrEmpty},
+		{"5 parsecs", 0, ErrUnknownUnit},
+		{"1.5h", 0, ErrEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrailingText(t *testing.T) {
+	for _, input := range []string{"2h spam", "1h30", "навсегда и ещё", "5 минут и"} {
+		if d, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %v, want error", input, d)
+		}
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     time.Duration
+		wantRest string
+		wantErr  error
+	}{
+		{"2ч спам в чате", 2 * time.Hour, "спам в чате", nil},
+		{"1h30m flood", time.Hour + 30*time.Minute, "flood", nil},
+		{"2 дня 3 часа за оскорбления", 2*day + 3*time.Hour, "за оскорбления", nil},
+		{"30 минут", 30 * time.Minute, "", nil},
+		{"навсегда реклама казино", Forever, "реклама казино", nil},
+		{"forever", Forever, "", nil},
+		// после первой длительности неизвестное слово - уже причина
+		{"1 день 2 раза подряд", day, "2 раза подряд", nil},
+		{"1h30", time.Hour, "30", nil},
+		{"5 минут и спам", 5 * time.Minute, "и спам", nil},
+
+		{"", 0, "", ErrEmpty},
+		{"спам 2ч", 0, "", ErrEmpty},
+		{"-2ч спам", 0, "", ErrEmpty},
+		{"2 раза", 0, "", ErrUnknownUnit},
+		{"99999999999999999999 h", 0, "", ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, rest, err := ParsePrefix(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParsePrefix(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want || rest != tt.wantRest {
+				t.Errorf("ParsePrefix(%q) = %v, %q, want %v, %q", tt.input, got, rest, tt.want, tt.wantRest)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package fuzzy - нечёткое сравнение слов для поиска по FAQ.
+//
+// Текст приводится к нижнему регистру, "ё" становится "е", знаки препинания
+// выкидываются. Слова сравниваются по расстоянию Левенштейна, а общий префикс
+// считается почти совпадением: "пропуска" и "пропуск" - одно и то же слово,
+// русские окончания иначе ломают поиск.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MinWordLen - более короткие слова ("в", "и", "на") в поиске не участвуют
+const MinWordLen = 3
+
+// minPrefix - с какой длины общий префикс считается тем же словом
+const minPrefix = 4
+
+// Normalize приводит текст к виду, в котором его удобно сравнивать
+func Normalize(text string) string {
+	text = strings.ToLower(text)
+	text = strings.ReplaceAll(text, "ё", "е")
+	return strings.Join(strings.FieldsFunc(text, isSeparator), " ")
+}
+
+// Words разбивает текст на нормализованные слова, короткие выбрасывает
+func Words(text string) []string {
+	var words []string
+	for _, word := range strings.Fields(Normalize(text)) {
+		if len([]rune(word)) >= MinWordLen {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Similarity - похожесть двух нормализованных слов от 0 до 1
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	if prefix := commonPrefix(ra, rb); prefix >= minPrefix && prefix == min(len(ra), len(rb)) {
+		return 0.9
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// BestMatch - насколько слово похоже на самое похожее из списка
+func BestMatch(word string, candidates []string) float64 {
+	best := 0.0
+	for _, candidate := range candidates {
+		if s := Similarity(word, candidate); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// Overlap - какая доля слов a нашлась среди слов b с похожестью не ниже threshold
+func Overlap(a, b []string, threshold float64) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+	found := 0
+	for _, word := range a {
+		if BestMatch(word, b) >= threshold {
+			found++
+		}
+	}
+	return float64(found) / float64(len(a))
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func commonPrefix(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,102 @@
+package fuzzy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"Ёжик, в ТУМАНЕ!", "ежик в тумане"},
+		{"wifi-пароль от S21", "wifi пароль от s21"},
+		{"...кофе???", "кофе"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"в и на", nil},
+		{"Как попасть в кампус?", []string{"как", "попасть", "кампус"}},
+		{"S21 go кампус", []string{"s21", "кампус"}},
+		{"ёлка", []string{"елка"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Words(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"same", "кампус", "кампус", 1},
+		{"ending", "пропуск", "пропуска", 0.9},
+		{"short prefix", "проп", "пропуск", 0.9},
+		{"prefix too short", "про", "пропуск", 1 - 4.0/7},
+		{"missing letter", "кампс", "кампус", 1 - 1.0/6},
+		{"wrong letter", "кафе", "кофе", 0.75},
+		{"swapped letters", "кмапус", "кампус", 1 - 2.0/6},
+		{"latin letter in cyrillic word", "kофе", "кофе", 0.75},
+		{"transliteration", "wifi", "вайфай", 0},
+		{"empty", "", "кофе", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := Similarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      []string
+		threshold float64
+		want      float64
+	}{
+		{"empty query", nil, []string{"кофе"}, 0.7, 0},
+		{"empty text", []string{"кофе"}, nil, 0.7, 0},
+		{"all found", []string{"как", "попасть", "кампус"}, []string{"кампус", "как", "попасть"}, 0.7, 1},
+		{"part found", []string{"как", "попасть", "кампус"}, []string{"кампус"}, 0.7, 1.0 / 3},
+		{"typo", []string{"кампс"}, []string{"кампус"}, 0.7, 1},
+		{"threshold is inclusive", []string{"кафе"}, []string{"кофе"}, 0.75, 1},
+		{"just below threshold", []string{"кафе"}, []string{"кофе"}, 0.76, 0},
+		{"mixed scripts", []string{"wifi", "пароль"}, []string{"пароль", "от", "wifi"}, 0.7, 1},
+		{"transliteration isn't found", []string{"wifi"}, []string{"вайфай"}, 0.7, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Overlap(tt.a, tt.b, tt.threshold); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Overlap(%q, %q, %v) = %v, want %v", tt.a, tt.b, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Package i18n - каталоги сообщений на нескольких языках.
+//
+// Каталог - JSON-объект "ключ": "текст". Текст - формат для fmt.Sprintf,
+// аргументы можно переставлять через %[2]s. Если слово зависит от числа,
+// вместо строки пишется объект с формами:
+//
+//	"minutes": {"one": "%d минута", "few": "%d минуты", "many": "%d минут"}
+//
+// Нужную форму выбирают правила языка: в русском 1 минута, 2 минуты, 5 минут,
+// в английском 1 minute, 2 minutes. Число подставляется первым аргументом.
+//
+// Если ключа нет в каталоге языка, берётся язык по умолчанию, если нет и там - сам ключ.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Form - форма слова для числа, названия как в CLDR
+type Form string
+
+const (
+	One   Form = "one"
+	Few   Form = "few"
+	Many  Form = "many"
+	Other Form = "other"
+)
+
+// PluralRule выбирает форму слова для числа
+type PluralRule func(n int64) Form
+
+var pluralRules = map[string]PluralRule{
+	"ru": russianPlural,
+	"en": englishPlural,
+}
+
+type message struct {
+	text  string
+	forms map[Form]string
+}
+
+// Bundle - каталоги всех языков. Заполняется при старте, дальше только читается.
+type Bundle struct {
+	fallback  string
+	languages []string
+	catalogs  map[string]map[string]message
+}
+
+// NewBundle создаёт пустой набор каталогов, fallback - язык по умолчанию
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{
+		fallback: fallback,
+		catalogs: make(map[string]map[string]message),
+	}
+}
+
+// Add разбирает JSON-каталог языка. Языки без правил множественного числа не поддерживаются.
+func (b *Bundle) Add(lang string, data []byte) error {
+	if _, ok := pluralRules[lang]; !ok {
+		return fmt.Errorf("i18n: no plural rule for %q", lang)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("i18n: catalog %q: %w", lang, err)
+	}
+
+	catalog := make(map[string]message, len(raw))
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			catalog[key] = message{text: text}
+			continue
+		}
+		var forms map[Form]string
+		if err := json.Unmarshal(value, &forms); err != nil {
+			return fmt.Errorf("i18n: catalog %q, key %q: must be a string or plural forms", lang, key)
+		}
+		if forms[Other] == "" && forms[Many] == "" {
+			return fmt.Errorf("i18n: catalog %q, key %q: no %q or %q form", lang, key, Other, Many)
+		}
+		catalog[key] = message{forms: forms}
+	}
+
+	if _, exists := b.catalogs[lang]; !exists {
+		b.languages = append(b.languages, lang)
+	}
+	b.catalogs[lang] = catalog
+	return nil
+}
+
+// Languages возвращает языки в порядке добавления
+func (b *Bundle) Languages() []string {
+	return b.languages
+}
+
+// Fallback - язык по умолчанию
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// Match подбирает поддерживаемый язык по коду вроде "en" или "en-US"
+func (b *Bundle) Match(code string) (string, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := b.catalogs[code]; ok {
+		return code, true
+	}
+	return "", false
+}
+
+// Has проверяет, есть ли ключ в каталоге языка или языка по умолчанию
+func (b *Bundle) Has(lang, key string) bool {
+	_, _, ok := b.lookup(lang, key)
+	return ok
+}
+
+// T возвращает сообщение по ключу
+func (b *Bundle) T(lang, key string, args ...any) string {
+	return fmt.Sprintf(b.Format(lang, key), args...)
+}
+
+// N возвращает сообщение в форме для числа n, n подставляется первым аргументом
+func (b *Bundle) N(lang, key string, n int64, args ...any) string {
+	return fmt.Sprintf(b.PluralFormat(lang, key, n), append([]any{n}, args...)...)
+}
+
+// Format возвращает формат сообщения без подстановки, например чтобы его экранировать.
+// Знак процента в каталоге пишется как %%. Ключа нет - возвращается сам ключ.
+func (b *Bundle) Format(lang, key string) string {
+	msg, _, ok := b.lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if msg.forms != nil {
+		return msg.form(Other)
+	}
+	return msg.text
+}
+
+// PluralFormat возвращает формат сообщения в форме для числа n
+func (b *Bundle) PluralFormat(lang, key string, n int64) string {
+	msg, found, ok := b.lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if msg.forms == nil {
+		return msg.text
+	}
+	// Формы выбираются по правилам того языка, из каталога которого взято сообщение
+	return msg.form(pluralRules[found](n))
+}
+
+// lookup ищет сообщение и возвращает язык каталога, где оно нашлось
+func (b *Bundle) lookup(lang, key string) (message, string, bool) {
+	if msg, ok := b.catalogs[lang][key]; ok {
+		return msg, lang, true
+	}
+	msg, ok := b.catalogs[b.fallback][key]
+	return msg, b.fallback, ok
+}
+
+// form возвращает нужную форму, а если её забыли перевести - самую общую
+func (m message) form(form Form) string {
+	for _, f := range []Form{form, Other, Many} {
+		if text, ok := m.forms[f]; ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// russianPlural: 1 минута, 2 минуты, 5 минут, 11 минут, 21 минута
+func russianPlural(n int64) Form {
+	n = n % 100
+	if n < 0 {
+		n = -n
+	}
+	if n >= 11 && n <= 14 {
+		return Many
+	}
+	switch n % 10 {
+	case 1:
+		return One
+	case 2, 3, 4:
+		return Few
+	}
+	return Many
+}
+
+// englishPlural: 1 minute, 2 minutes, 0 minutes
+func englishPlural(n int64) Form {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
@@ -0,0 +1,212 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRussianPlural(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want Form
+	}{
+		{0, Many},
+		{1, One},
+		{2, Few},
+		{4, Few},
+		{5, Many},
+		{11, Many},
+		{12, Many},
+		{14, Many},
+		{21, One},
+		{22, Few},
+		{25, Many},
+		{101, One},
+		{104, Few},
+		{111, Many},
+		{112, Many},
+		{121, One},
+		{-1, One},
+		{-21, One},
+		{-111, Many},
+	}
+	for _, tt := range tests {
+		if got := russianPlural(tt.n); got != tt.want {
+			t.Errorf("russianPlural(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestEnglishPlural(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want Form
+	}{
+		{0, Other},
+		{1, One},
+		{2, Other},
+		{5, Other},
+		{11, Other},
+		{21, Other},
+		{111, Other},
+		{-1, Other},
+	}
+	for _, tt := range tests {
+		if got := englishPlural(tt.n); got != tt.want {
+			t.Errorf("englishPlural(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestBundleAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    string
+		data    string
+		wantErr bool
+	}{
+		{"strings and forms", "ru", `{"hi": "Привет", "minutes": {"one": "%d минута", "many": "%d минут"}}`, false},
+		{"only other form", "en", `{"minutes": {"other": "%d minutes"}}`, false},
+		{"language without plural rule", "de", `{"hi": "Hallo"}`, true},
+		{"broken json", "ru", `{"hi": `, true},
+		{"number instead of text", "ru", `{"hi": 42}`, true},
+		{"no general form", "ru", `{"minutes": {"one": "%d минута", "few": "%d минуты"}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBundle("ru")
+			err := b.Add(tt.lang, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Add = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && len(b.Languages()) != 0 {
+				t.Errorf("rejected catalog added language: %v", b.Languages())
+			}
+		})
+	}
+}
+
+func TestBundleAddReplacesCatalog(t *testing.T) {
+	b := NewBundle("ru")
+	for _, data := range []string{`{"hi": "Привет"}`, `{"bye": "Пока"}`} {
+		if err := b.Add("ru", []byte(data)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if got := b.Languages(); !reflect.DeepEqual(got, []string{"ru"}) {
+		t.Errorf("Languages = %v, want [ru]", got)
+	}
+	if b.Has("ru", "hi") {
+		t.Error("old catalog survived the second Add")
+	}
+}
+
+// newTestBundle - русский по умолчанию и неполный английский
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b := NewBundle("ru")
+	catalogs := map[string]string{
+		"ru": `{
+			"hi": "Привет, %s",
+			"swap": "%[2]s и %[1]s",
+			"percent": "%d%% готово",
+			"only_ru": "Только по-русски",
+			"minutes": {"one": "%d минута", "few": "%d минуты", "many": "%d минут"},
+			"users": {"one": "%d пользователь", "many": "%d пользователей"},
+			"late": {"one": "%d минута в %s", "few": "%d минуты в %s", "many": "%d минут в %s"}
+		}`,
+		"en": `{
+			"hi": "Hi, %s",
+			"minutes": {"one": "%d minute", "other": "%d minutes"}
+		}`,
+	}
+	for _, lang := range []string{"ru", "en"} {
+		if err := b.Add(lang, []byte(catalogs[lang])); err != nil {
+			t.Fatalf("Add(%s): %v", lang, err)
+		}
+	}
+	return b
+}
+
+func TestBundleT(t *testing.T) {
+	tests := []struct {
+		lang string
+		key  string
+		args []any
+		want string
+	}{
+		{"ru", "hi", []any{"Морти"}, "Привет, Морти"},
+		{"en", "hi", []any{"Morty"}, "Hi, Morty"},
+		{"ru", "swap", []any{"Рик", "Морти"}, "Морти и Рик"},
+		{"ru", "percent", []any{42}, "42% готово"},
+		{"en", "only_ru", nil, "Только по-русски"},
+		{"de", "hi", []any{"Morty"}, "Привет, Morty"},
+		{"en", "missing", nil, "missing"},
+		{"ru", "minutes", []any{5}, "5 минут"},
+	}
+	b := newTestBundle(t)
+	for _, tt := range tests {
+		t.Run(tt.lang+"/"+tt.key, func(t *testing.T) {
+			if got := b.T(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleN(t *testing.T) {
+	tests := []struct {
+		lang string
+		key  string
+		n    int64
+		args []any
+		want string
+	}{
+		{"ru", "minutes", 1, nil, "1 минута"},
+		{"ru", "minutes", 2, nil, "2 минуты"},
+		{"ru", "minutes", 5, nil, "5 минут"},
+		{"ru", "minutes", 11, nil, "11 минут"},
+		{"ru", "minutes", 21, nil, "21 минута"},
+		{"ru", "minutes", 111, nil, "111 минут"},
+		{"en", "minutes", 1, nil, "1 minute"},
+		{"en", "minutes", 2, nil, "2 minutes"},
+		{"en", "minutes", 21, nil, "21 minutes"},
+		// Формы few нет - берётся many
+		{"ru", "users", 2, nil, "2 пользователей"},
+		{"ru", "users", 21, nil, "21 пользователь"},
+		// Ключа нет в английском - русский каталог и русские правила
+		{"en", "users", 21, nil, "21 пользователь"},
+		{"ru", "late", 3, []any{"школу"}, "3 минуты в школу"},
+		// Сообщение без форм - число всё равно первый аргумент
+		{"ru", "percent", 7, nil, "7% готово"},
+	}
+	b := newTestBundle(t)
+	for _, tt := range tests {
+		t.Run(tt.lang+"/"+tt.key+"/"+tt.want, func(t *testing.T) {
+			if got := b.N(tt.lang, tt.key, tt.n, tt.args...); got != tt.want {
+				t.Errorf("N(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleMatch(t *testing.T) {
+	tests := []struct {
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{"en", "en", true},
+		{"en-US", "en", true},
+		{" RU_ru ", "ru", true},
+		{"de", "", false},
+		{"", "", false},
+	}
+	b := newTestBundle(t)
+	for _, tt := range tests {
+		got, ok := b.Match(tt.code)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Match(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}